@@ -0,0 +1,23 @@
+package cache
+
+import (
+	"path/filepath"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// AttachmentStore is implemented by caches that keep downloaded attachment
+// files alongside cached issues
+type AttachmentStore interface {
+	AttachmentPath(issue *models.IssueWithHistory, attachment models.Attachment) string
+}
+
+// Ensure DiskCache satisfies the AttachmentStore interface
+var _ AttachmentStore = (*DiskCache)(nil)
+
+// AttachmentPath returns where an attachment of issue is stored
+// Format: .data/jira/<hostname>/attachments/<issue id>/<attachment id>-<filename>
+// The filename is escaped so it is safe on any filesystem.
+func (d *DiskCache) AttachmentPath(issue *models.IssueWithHistory, attachment models.Attachment) string {
+	return filepath.Join(d.getDataPath(), "attachments", issue.ID, attachment.ID+"-"+safeFilename(attachment.Filename))
+}
@@ -0,0 +1,77 @@
+package cache
+
+import "time"
+
+// BatchChecker is implemented by caches that can answer existence and
+// freshness questions for many keys at once, e.g. during incremental planning
+type BatchChecker interface {
+	ExistsBatch(keys []string) map[string]bool
+	LastFetchedBatch(keys []string) map[string]time.Time
+}
+
+// Ensure the caches satisfy the BatchChecker interface
+var (
+	_ BatchChecker = (*DiskCache)(nil)
+	_ BatchChecker = (*ShardedCache)(nil)
+)
+
+// ExistsBatch reports which of keys are cached, with a single directory read
+func (d *DiskCache) ExistsBatch(keys []string) map[string]bool {
+	cached := map[string]bool{}
+	if all, err := d.listKeys(); err == nil {
+		for _, key := range all {
+			cached[key] = true
+		}
+	}
+
+	exists := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		exists[key] = cached[key]
+	}
+	return exists
+}
+
+// LastFetchedBatch returns approximate fetch times for the cached keys,
+// taken from the index entries (which are rewritten on every write) rather
+// than by opening each issue file. Keys that are not cached are omitted.
+func (d *DiskCache) LastFetchedBatch(keys []string) map[string]time.Time {
+	times, err := d.keyModTimes()
+	if err != nil {
+		return map[string]time.Time{}
+	}
+
+	fetched := make(map[string]time.Time, len(keys))
+	for _, key := range keys {
+		if t, ok := times[key]; ok {
+			fetched[key] = t
+		}
+	}
+	return fetched
+}
+
+// ExistsBatch reports which of keys are cached
+func (s *ShardedCache) ExistsBatch(keys []string) map[string]bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	exists := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		_, exists[key] = s.index.Entries[key]
+	}
+	return exists
+}
+
+// LastFetchedBatch returns fetch times for the cached keys; keys that are
+// not cached are omitted
+func (s *ShardedCache) LastFetchedBatch(keys []string) map[string]time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	fetched := make(map[string]time.Time, len(keys))
+	for _, key := range keys {
+		if entry, ok := s.index.Entries[key]; ok {
+			fetched[key] = entry.FetchedAt
+		}
+	}
+	return fetched
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// Cache is the storage backend used by the scraper. DiskCache is the default
+// implementation; alternatives (in-memory, object storage) can be swapped in
+// without changing scraper logic.
+type Cache interface {
+	// WriteIssue stores an issue with the API call duration as metadata
+	WriteIssue(issue *models.IssueWithHistory, duration time.Duration) (string, error)
+
+	// WriteIssueWithMetadata stores an issue with caller-supplied metadata
+	WriteIssueWithMetadata(issue *models.IssueWithHistory, meta models.CacheMetadata) (string, error)
+
+	// GetIssue retrieves a cached issue by key
+	GetIssue(key string) (*models.CachedIssue, error)
+
+	// Exists checks if an issue is cached
+	Exists(key string) bool
+
+	// ListIssues returns all cached issue keys
+	ListIssues() ([]string, error)
+
+	// GetLastFetched returns when an issue was last fetched
+	GetLastFetched(key string) (time.Time, error)
+}
+
+// Ensure DiskCache satisfies the Cache interface
+var _ Cache = (*DiskCache)(nil)
+
+// Version is the scraper version recorded in cache metadata. Release builds
+// override it with:
+//
+//	-ldflags "-X github.com/jctanner/go-jira-scraper/pkg/cache.Version=1.2.3"
+var Version = "0.1.0"
+
+// DefaultFetchedBy returns the fetched_by stamp for this build
+func DefaultFetchedBy() string {
+	return "go-jira-scraper/" + Version
+}
+
+// stampMetadata fills in the write-time fields of cache metadata, using
+// fetchedBy (or DefaultFetchedBy when empty) unless the caller set one
+func stampMetadata(meta *models.CacheMetadata, fetchedBy string) {
+	if meta.FetchedAt.IsZero() {
+		meta.FetchedAt = time.Now().UTC()
+	}
+	if meta.FetchedBy == "" {
+		meta.FetchedBy = fetchedBy
+	}
+	if meta.FetchedBy == "" {
+		meta.FetchedBy = DefaultFetchedBy()
+	}
+	meta.SchemaVersion = models.CurrentSchemaVersion
+}
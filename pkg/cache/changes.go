@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// ChangeDetector is implemented by caches that can tell whether a fetched
+// issue differs from the stored copy, so unchanged issues are not rewritten
+type ChangeDetector interface {
+	// WriteIssueIfChanged stores the issue unless the cached copy has the
+	// same content, reporting whether anything was written
+	WriteIssueIfChanged(issue *models.IssueWithHistory, meta models.CacheMetadata) (bool, error)
+}
+
+// Ensure DiskCache satisfies the ChangeDetector interface
+var _ ChangeDetector = (*DiskCache)(nil)
+
+// WriteIssueIfChanged stores an issue only when its content hash or fetch
+// metadata differs from the cached copy. Skipped issues keep their previous
+// fetched_at.
+func (d *DiskCache) WriteIssueIfChanged(issue *models.IssueWithHistory, meta models.CacheMetadata) (bool, error) {
+	_, changed, err := d.writeIssue(issue, meta, true)
+	return changed, err
+}
+
+// contentHash returns the SHA-256 of the issue's canonical JSON encoding.
+// encoding/json emits struct fields in declaration order and map keys
+// sorted, so equal issues always hash the same.
+func contentHash(issue *models.IssueWithHistory) (string, error) {
+	data, err := json.Marshal(issue)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash issue: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// sameContent reports whether a new write would store the same issue under
+// the same conditional-request and rename metadata, remote links and
+// transitions as the previous one
+func sameContent(previous, next models.CacheMetadata) bool {
+	if previous.ContentHash == "" || previous.ContentHash != next.ContentHash {
+		return false
+	}
+	if extrasJSON(previous) != extrasJSON(next) {
+		return false
+	}
+	if previous.ETag != next.ETag || previous.LastModified != next.LastModified {
+		return false
+	}
+	if (previous.Renamed == nil) != (next.Renamed == nil) {
+		return false
+	}
+	return previous.Renamed == nil || previous.Renamed.FromKey == next.Renamed.FromKey
+}
+
+// extrasJSON encodes a record's remote links and transitions for
+// comparison, treating absent and empty lists alike
+func extrasJSON(meta models.CacheMetadata) string {
+	var links, transitions any
+	if len(meta.RemoteLinks) > 0 {
+		links = meta.RemoteLinks
+	}
+	if len(meta.Transitions) > 0 {
+		transitions = meta.Transitions
+	}
+	data, _ := json.Marshal([]any{links, transitions})
+	return string(data)
+}
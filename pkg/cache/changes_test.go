@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+func TestWriteIssueIfChangedComparesFetchedExtras(t *testing.T) {
+	d := newTestCache(t)
+	issue := testIssue("1", "PROJ-1", "summary")
+	links := []models.RemoteLink{{ID: 10, Object: models.RemoteLinkObject{URL: "https://example.com/pr/1", Title: "PR 1"}}}
+
+	steps := []struct {
+		name        string
+		meta        models.CacheMetadata
+		wantChanged bool
+	}{
+		{"first write", models.CacheMetadata{}, true},
+		{"empty lists", models.CacheMetadata{RemoteLinks: []models.RemoteLink{}, Transitions: []models.AvailableTransition{}}, false},
+		{"new remote link", models.CacheMetadata{RemoteLinks: links}, true},
+		{"same remote link", models.CacheMetadata{RemoteLinks: links}, false},
+		{"new transition", models.CacheMetadata{RemoteLinks: links, Transitions: []models.AvailableTransition{{ID: "21", Name: "Close"}}}, true},
+		{"transition removed", models.CacheMetadata{RemoteLinks: links}, true},
+	}
+	for _, step := range steps {
+		changed, err := d.WriteIssueIfChanged(issue, step.meta)
+		if err != nil {
+			t.Fatalf("%s: WriteIssueIfChanged: %v", step.name, err)
+		}
+		if changed != step.wantChanged {
+			t.Errorf("%s: changed = %v, want %v", step.name, changed, step.wantChanged)
+		}
+	}
+}
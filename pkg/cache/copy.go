@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// SyncOptions selects the records SyncCache copies
+type SyncOptions struct {
+	Projects     []string  // Only copy issues in these projects (default: all)
+	FetchedAfter time.Time // Only copy issues fetched after this time (default: all)
+}
+
+// SyncCache copies the issues of this cache into dst, e.g. to back up a
+// cache, move it between hosts, or merge the partial caches of parallel
+// workers. An issue already in dst is only replaced when this cache's copy
+// was fetched later. Fetch metadata is preserved, dst's redaction rules
+// apply, and raw responses are copied when dst stores them. It returns the
+// number of issues copied.
+func (d *DiskCache) SyncCache(dst *DiskCache, opts SyncOptions) (int, error) {
+	var keys []string
+	var err error
+	if opts.FetchedAfter.IsZero() {
+		keys, err = d.ListIssues()
+	} else {
+		keys, err = d.ListIssuesFetchedAfter(opts.FetchedAfter)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	copied := 0
+	for _, key := range keys {
+		if !inProjects(key, opts.Projects) {
+			continue
+		}
+
+		cached, err := d.GetIssue(key)
+		if err != nil {
+			return copied, fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		issue := cached.JiraData
+		if issue == nil || issue.Key != key {
+			// Skip empty records and alias links (e.g. moved issues)
+			continue
+		}
+
+		existing, err := dst.GetIssueByID(issue.ID)
+		if err != nil {
+			existing, err = dst.GetIssue(key)
+		}
+		if err == nil && existing.JiraData != nil &&
+			!cached.CacheMetadata.FetchedAt.After(existing.CacheMetadata.FetchedAt) {
+			continue
+		}
+
+		if _, err := dst.WriteIssueWithMetadata(issue, cached.CacheMetadata); err != nil {
+			return copied, fmt.Errorf("failed to write %s: %w", key, err)
+		}
+		if raw, err := os.ReadFile(d.rawPath(issue.ID)); err == nil {
+			if err := dst.WriteRaw(issue.ID, raw); err != nil {
+				log.Printf("Warning: failed to copy raw response for %s: %v", key, err)
+			}
+		}
+		copied++
+	}
+
+	log.Printf("Synced %d issues into %s", copied, dst.getDataPath())
+	return copied, nil
+}
+
+// inProjects reports whether key belongs to one of projects, or whether
+// projects is empty
+func inProjects(key string, projects []string) bool {
+	if len(projects) == 0 {
+		return true
+	}
+	for _, project := range projects {
+		if strings.HasPrefix(key, project+"-") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+package cache
+
+// CurrentKeyLister is implemented by caches that can list issue keys
+// without the old keys of moved issues, so callers can count and page
+// through issues without reading every record
+type CurrentKeyLister interface {
+	ListCurrentIssues() ([]string, error)
+}
+
+// Ensure DiskCache satisfies the CurrentKeyLister interface
+var _ CurrentKeyLister = (*DiskCache)(nil)
+
+// ListCurrentIssues returns all cached issue keys except the old keys of
+// moved issues. Only records reached through more than one key are read.
+func (d *DiskCache) ListCurrentIssues() ([]string, error) {
+	keys, err := d.ListIssues()
+	if err != nil {
+		return nil, err
+	}
+	return d.dropAliases(keys), nil
+}
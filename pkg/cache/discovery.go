@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// discoveryCheckpointTTL bounds how old a discovery checkpoint may be and
+// still be resumed; older progress is likely to have drifted too far
+const discoveryCheckpointTTL = 24 * time.Hour
+
+// discoveryPage is one entry of a discovery checkpoint log: the issues
+// found since the previous entry and where the search continues. The first
+// entry also names the query.
+type discoveryPage struct {
+	JQL     string          `json:"jql,omitempty"`
+	StartAt int             `json:"start_at"`
+	SavedAt time.Time       `json:"saved_at"`
+	Issues  []*models.Issue `json:"issues"`
+}
+
+// discoveryPath returns the checkpoint log for a JQL query, one
+// discoveryPage per line
+// Format: .data/jira/<hostname>/.discovery/<sha256 of jql>.jsonl
+func (d *DiskCache) discoveryPath(jql string) string {
+	sum := sha256.Sum256([]byte(jql))
+	return filepath.Join(d.getDataPath(), ".discovery", hex.EncodeToString(sum[:])+".jsonl")
+}
+
+// LoadDiscovery returns the saved progress of a search, if a recent
+// checkpoint exists for exactly this query. A partial last entry, left by
+// an interrupted save, is truncated away so later saves append cleanly.
+func (d *DiskCache) LoadDiscovery(jql string) (int, []*models.Issue, bool) {
+	path := d.discoveryPath(jql)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, nil, false
+	}
+
+	var last discoveryPage
+	var issues []*models.Issue
+	valid := 0
+	for valid < len(data) {
+		end := bytes.IndexByte(data[valid:], '\n')
+		if end < 0 {
+			break
+		}
+		var page discoveryPage
+		if err := json.Unmarshal(data[valid:valid+end], &page); err != nil {
+			break
+		}
+		if valid == 0 && page.JQL != jql {
+			return 0, nil, false
+		}
+		issues = append(issues, page.Issues...)
+		last = page
+		valid += end + 1
+	}
+	if valid < len(data) {
+		if err := os.Truncate(path, int64(valid)); err != nil {
+			return 0, nil, false
+		}
+	}
+
+	if last.SavedAt.IsZero() || time.Since(last.SavedAt) > discoveryCheckpointTTL {
+		return 0, nil, false
+	}
+	return last.StartAt, issues, true
+}
+
+// AppendDiscovery records the progress of a search: the issues found since
+// the previous save and the result to continue from. Each save appends one
+// line, so its cost does not grow with the number of issues found.
+func (d *DiskCache) AppendDiscovery(jql string, startAt int, issues []*models.Issue) error {
+	path := d.discoveryPath(jql)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create discovery directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open discovery checkpoint: %w", err)
+	}
+
+	page := discoveryPage{StartAt: startAt, SavedAt: time.Now().UTC(), Issues: issues}
+	if info, err := file.Stat(); err == nil && info.Size() == 0 {
+		page.JQL = jql
+	}
+	data, err := json.Marshal(page)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to marshal discovery checkpoint: %w", err)
+	}
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write discovery checkpoint: %w", err)
+	}
+	return file.Close()
+}
+
+// ClearDiscovery removes the checkpoint of a completed search
+func (d *DiskCache) ClearDiscovery(jql string) error {
+	if err := os.Remove(d.discoveryPath(jql)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove discovery checkpoint: %w", err)
+	}
+	return nil
+}
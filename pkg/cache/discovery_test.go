@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// discoveryKeys returns the keys of issues
+func discoveryKeys(issues []*models.Issue) []string {
+	keys := make([]string, len(issues))
+	for i, issue := range issues {
+		keys[i] = issue.Key
+	}
+	return keys
+}
+
+func TestDiscoveryLogAppendsPages(t *testing.T) {
+	d := newTestCache(t)
+	const jql = "project = PROJ"
+
+	if _, _, ok := d.LoadDiscovery(jql); ok {
+		t.Fatal("LoadDiscovery found a checkpoint in an empty cache")
+	}
+	pages := [][]*models.Issue{
+		{{Key: "PROJ-1"}, {Key: "PROJ-2"}},
+		{{Key: "PROJ-3"}},
+	}
+	for i, page := range pages {
+		if err := d.AppendDiscovery(jql, 2*(i+1), page); err != nil {
+			t.Fatalf("AppendDiscovery: %v", err)
+		}
+	}
+
+	startAt, issues, ok := d.LoadDiscovery(jql)
+	if !ok || startAt != 4 || len(issues) != 3 || issues[2].Key != "PROJ-3" {
+		t.Errorf("LoadDiscovery = %d, %v, %v, want 4, [PROJ-1 PROJ-2 PROJ-3], true", startAt, discoveryKeys(issues), ok)
+	}
+	if _, _, ok := d.LoadDiscovery("project = OTHER"); ok {
+		t.Error("LoadDiscovery resumed a different query")
+	}
+
+	if err := d.ClearDiscovery(jql); err != nil {
+		t.Fatalf("ClearDiscovery: %v", err)
+	}
+	if _, _, ok := d.LoadDiscovery(jql); ok {
+		t.Error("LoadDiscovery found a cleared checkpoint")
+	}
+}
+
+func TestDiscoveryLogTruncatesPartialPage(t *testing.T) {
+	d := newTestCache(t)
+	const jql = "project = PROJ"
+	if err := d.AppendDiscovery(jql, 2, []*models.Issue{{Key: "PROJ-1"}, {Key: "PROJ-2"}}); err != nil {
+		t.Fatalf("AppendDiscovery: %v", err)
+	}
+
+	// Simulate a save interrupted mid-line
+	file, err := os.OpenFile(d.discoveryPath(jql), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	file.WriteString(`{"start_at":4,"issues":[{"key":"PROJ-3"`)
+	file.Close()
+
+	startAt, issues, ok := d.LoadDiscovery(jql)
+	if !ok || startAt != 2 || len(issues) != 2 {
+		t.Fatalf("LoadDiscovery = %d, %v, %v, want the last complete page", startAt, discoveryKeys(issues), ok)
+	}
+
+	// Progress saved after resuming is readable
+	if err := d.AppendDiscovery(jql, 4, []*models.Issue{{Key: "PROJ-3"}}); err != nil {
+		t.Fatalf("AppendDiscovery: %v", err)
+	}
+	if startAt, issues, ok := d.LoadDiscovery(jql); !ok || startAt != 4 || len(issues) != 3 {
+		t.Errorf("LoadDiscovery = %d, %v, %v, want 4 and 3 issues", startAt, discoveryKeys(issues), ok)
+	}
+}
@@ -3,19 +3,49 @@ package cache
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/jctanner/go-jira-scraper/pkg/history"
 	"github.com/jctanner/go-jira-scraper/pkg/models"
 )
 
-// DiskCache manages the local disk cache for JIRA issues
+// DiskCache manages the local disk cache for JIRA issues.
+//
+// A DiskCache is safe for concurrent use by multiple goroutines. Writes and
+// reads of the same issue are serialized by a striped lock keyed on issue
+// key and ID, and files and by_key entries are replaced atomically via
+// rename, so readers see either the previous or the new record, never a
+// partial one. The locks do not coordinate separate processes; concurrent
+// processes writing the same issue may interleave, but the last complete
+// write wins.
 type DiskCache struct {
-	baseDir  string
-	jiraHost string // Hostname of JIRA instance for namespacing
+	baseDir   string
+	jiraHost  string // Hostname of JIRA instance for namespacing
+	namespace string // Optional subdirectory separating environments on one host
+
+	locks     stripedLock
+	redaction RedactionConfig
+	pretty    bool   // Indent issue JSON; compact output roughly halves disk usage
+	fetchedBy string // Stamp recorded in metadata; defaults to DefaultFetchedBy
+	storeRaw  bool   // Keep raw API responses under raw/
+
+	indexMu sync.Mutex // Guards the index/ reverse index files
+
+	indexStrategy IndexStrategy     // How keys map to by_id files; "" means IndexSymlink
+	keyMapMu      sync.Mutex        // Guards keyMap
+	keyMap        map[string]string // Key -> ID under IndexNone; nil until loaded
+
+	layout Layout // Where records are stored; "" means LayoutByID
+
+	intern bool       // Store changelogs interned in the dictionary
+	dict   stringDict // Dictionary of interned strings; loaded on first use
 }
 
 // New creates a new DiskCache instance
@@ -23,6 +53,7 @@ func New(baseDir string) *DiskCache {
 	return &DiskCache{
 		baseDir:  baseDir,
 		jiraHost: "", // Will be set when Initialize is called with jiraURL
+		pretty:   true,
 	}
 }
 
@@ -32,9 +63,32 @@ func NewWithHost(baseDir string, jiraURL string) *DiskCache {
 	return &DiskCache{
 		baseDir:  baseDir,
 		jiraHost: host,
+		pretty:   true,
 	}
 }
 
+// SetPretty selects indented (true, the default) or compact JSON for
+// cached issue files. Readers accept either.
+func (d *DiskCache) SetPretty(pretty bool) {
+	d.pretty = pretty
+}
+
+// SetFetchedBy sets the tool name and version recorded in the metadata of
+// issues written without one, so records from different tools sharing a
+// cache can be told apart
+func (d *DiskCache) SetFetchedBy(fetchedBy string) {
+	d.fetchedBy = fetchedBy
+}
+
+// SetStoreRaw enables keeping each issue's raw API response alongside the
+// parsed record, so issues can be re-parsed later without re-scraping.
+// Raw responses are not stored while redaction is configured, and a
+// warning is logged if it is.
+func (d *DiskCache) SetStoreRaw(storeRaw bool) {
+	d.storeRaw = storeRaw
+	d.warnRawRedacted()
+}
+
 // extractHostname extracts the hostname from a JIRA URL
 func extractHostname(jiraURL string) string {
 	parsed, err := url.Parse(jiraURL)
@@ -46,9 +100,12 @@ func extractHostname(jiraURL string) string {
 }
 
 // getDataPath returns the base path for data storage
-// Format: .data/jira/<hostname>/
+// Format: .data/jira/<hostname>/[<namespace>/]
 func (d *DiskCache) getDataPath() string {
 	if d.jiraHost != "" {
+		if d.namespace != "" {
+			return filepath.Join(d.baseDir, "jira", d.jiraHost, d.namespace)
+		}
 		return filepath.Join(d.baseDir, "jira", d.jiraHost)
 	}
 	// Backward compatibility: if no host set, use old structure
@@ -74,63 +131,217 @@ func (d *DiskCache) Initialize() error {
 
 // WriteIssue stores an issue to disk with fetch metadata
 func (d *DiskCache) WriteIssue(issue *models.IssueWithHistory, duration time.Duration) (string, error) {
-	dataPath := d.getDataPath()
-	
+	return d.WriteIssueWithMetadata(issue, models.CacheMetadata{
+		APICallDurationMS: duration.Milliseconds(),
+	})
+}
+
+// WriteIssueWithMetadata stores an issue to disk with caller-supplied metadata.
+// FetchedAt and FetchedBy are filled in when left empty.
+func (d *DiskCache) WriteIssueWithMetadata(issue *models.IssueWithHistory, meta models.CacheMetadata) (string, error) {
+	path, _, err := d.writeIssue(issue, meta, false)
+	return path, err
+}
+
+// writeIssue stores an issue and maintains its indexes. When skipUnchanged
+// is set and the stored copy has the same content hash and metadata, the
+// file is left alone and changed is false.
+func (d *DiskCache) writeIssue(issue *models.IssueWithHistory, meta models.CacheMetadata, skipUnchanged bool) (path string, changed bool, err error) {
+	stampMetadata(&meta, d.fetchedBy)
+
+	// Strip personal data before anything touches disk
+	issue, err = d.redaction.Redact(issue)
+	if err != nil {
+		return "", false, err
+	}
+
+	meta.ContentHash, err = contentHash(issue)
+	if err != nil {
+		return "", false, err
+	}
+
 	// Wrap with cache metadata
 	cached := &models.CachedIssue{
-		CacheMetadata: models.CacheMetadata{
-			FetchedAt:         time.Now().UTC(),
-			FetchedBy:         "go-jira-scraper/0.1.0",
-			APICallDurationMS: duration.Milliseconds(),
-		},
-		JiraData: issue,
+		CacheMetadata: meta,
+		JiraData:      issue,
 	}
 
-	// Marshal to JSON
-	data, err := json.MarshalIndent(cached, "", "  ")
+	data, err := d.marshalIssue(cached)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal issue: %w", err)
+		return "", false, err
+	}
+
+	lockNames := []string{"key:" + issue.Key, "id:" + issue.ID}
+	if meta.Renamed != nil {
+		lockNames = append(lockNames, "key:"+meta.Renamed.FromKey)
+	}
+	unlock := d.locks.lock(lockNames...)
+	defer unlock()
+
+	// Note the previous version's index entry before replacing it. Under
+	// layouts that name records by key, a moved issue's previous record
+	// is found through its old key.
+	recordPath := d.recordPath(issue)
+	previousPath := recordPath
+	if _, err := os.Stat(recordPath); err != nil && meta.Renamed != nil {
+		if path, ok := d.issuePath(meta.Renamed.FromKey); ok {
+			previousPath = path
+		}
+	}
+	var oldKey, oldAssignee, oldRecord string
+	if previous, err := d.readIssueFile(previousPath); err == nil && previous.JiraData != nil {
+		if skipUnchanged && previousPath == recordPath && sameContent(previous.CacheMetadata, meta) {
+			return recordPath, false, nil
+		}
+		oldKey = previous.JiraData.Key
+		oldAssignee = assigneeName(previous.JiraData)
+		oldRecord = d.recordPath(previous.JiraData)
 	}
 
-	// Write to by_id directory
-	idPath := filepath.Join(dataPath, "by_id", issue.ID+".json")
-	if err := os.WriteFile(idPath, data, 0644); err != nil {
-		return "", fmt.Errorf("failed to write issue file: %w", err)
+	if err := d.writeRecord(recordPath, data); err != nil {
+		return "", false, err
+	}
+	if d.layout == LayoutDated && oldRecord != "" && oldRecord != recordPath {
+		if err := os.Remove(oldRecord); err != nil && !os.IsNotExist(err) {
+			log.Printf("Warning: failed to remove previous record of %s: %v", issue.Key, err)
+		}
 	}
 
-	// Create symlink in by_key directory
-	keyPath := filepath.Join(dataPath, "by_key", issue.Key+".json")
-	relPath := filepath.Join("..", "by_id", issue.ID+".json")
+	// Index the key, replacing any existing entry
+	if err := d.linkKey(issue.Key, issue); err != nil {
+		// Not fatal if the index entry fails (e.g., symlinks on Windows
+		// without permissions); the file is still accessible via by_id
+		fmt.Fprintf(os.Stderr, "Warning: failed to index key %s: %v\n", issue.Key, err)
+	}
 
-	// Remove existing symlink if it exists
-	os.Remove(keyPath)
+	// Keep the issue reachable under its old key after a move
+	if meta.Renamed != nil && meta.Renamed.FromKey != "" && meta.Renamed.FromKey != issue.Key {
+		if err := d.linkKey(meta.Renamed.FromKey, issue); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to index key %s: %v\n", meta.Renamed.FromKey, err)
+		}
+	}
 
-	// Create new symlink
-	if err := os.Symlink(relPath, keyPath); err != nil {
-		// Not fatal if symlink creation fails (e.g., on Windows without permissions)
-		// The file is still accessible via by_id
-		fmt.Fprintf(os.Stderr, "Warning: failed to create symlink %s: %v\n", keyPath, err)
+	if err := d.updateAssigneeIndex(oldKey, oldAssignee, issue.Key, assigneeName(issue)); err != nil {
+		// The index can be rebuilt with Reindex
+		log.Printf("Warning: failed to update assignee index for %s: %v", issue.Key, err)
 	}
 
-	return idPath, nil
+	return recordPath, true, nil
+}
+
+// marshalIssue encodes a cache record in the configured layout
+func (d *DiskCache) marshalIssue(cached *models.CachedIssue) ([]byte, error) {
+	record, err := d.internRecord(cached)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if d.pretty {
+		data, err = json.MarshalIndent(record, "", "  ")
+	} else {
+		data, err = json.Marshal(record)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal issue: %w", err)
+	}
+	return data, nil
 }
 
 // GetIssue retrieves an issue from disk by key
 func (d *DiskCache) GetIssue(key string) (*models.CachedIssue, error) {
-	dataPath := d.getDataPath()
-	keyPath := filepath.Join(dataPath, "by_key", key+".json")
-	return d.readIssueFile(keyPath)
+	unlock := d.locks.rlock("key:" + key)
+	defer unlock()
+
+	path, ok := d.issuePath(key)
+	if !ok {
+		return nil, fmt.Errorf("issue not found in cache")
+	}
+	return d.readIssueFile(path)
 }
 
-// GetIssueByID retrieves an issue from disk by ID
+// DeleteIssue removes an issue from the cache: its by_id file, its by_key
+// entry and that of any key it was moved from, its raw response, and its
+// assignee index entry. Deleting an issue that is not cached is not an error.
+func (d *DiskCache) DeleteIssue(key string) error {
+	cached, err := d.GetIssue(key)
+	if err != nil || cached.JiraData == nil {
+		// Nothing readable to resolve; drop a dangling key entry if any
+		return d.removeKeyIndex(key)
+	}
+	issue := cached.JiraData
+
+	keys := []string{key, issue.Key}
+	if renamed := cached.CacheMetadata.Renamed; renamed != nil && renamed.FromKey != "" {
+		keys = append(keys, renamed.FromKey)
+	}
+	lockNames := []string{"id:" + issue.ID}
+	for _, k := range keys {
+		lockNames = append(lockNames, "key:"+k)
+	}
+	unlock := d.locks.lock(lockNames...)
+	defer unlock()
+
+	for _, k := range keys {
+		if err := d.removeKeyIndex(k); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(d.recordPath(issue)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove issue file: %w", err)
+	}
+	if err := os.Remove(d.rawPath(issue.ID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove raw response: %w", err)
+	}
+
+	if err := d.updateAssigneeIndex(issue.Key, assigneeName(issue), "", ""); err != nil {
+		log.Printf("Warning: failed to update assignee index for %s: %v", issue.Key, err)
+	}
+	return nil
+}
+
+// GetIssueByID retrieves an issue from disk by ID. Only LayoutByID stores
+// issues by ID.
 func (d *DiskCache) GetIssueByID(id string) (*models.CachedIssue, error) {
+	if d.layout != "" && d.layout != LayoutByID {
+		return nil, fmt.Errorf("lookup by ID is not supported by the %s layout", d.layout)
+	}
 	dataPath := d.getDataPath()
 	idPath := filepath.Join(dataPath, "by_id", id+".json")
+
+	unlock := d.locks.rlock("id:" + id)
+	defer unlock()
 	return d.readIssueFile(idPath)
 }
 
-// readIssueFile reads and unmarshals an issue file
+// GetIssueTransitions returns the status transitions of a cached issue,
+// oldest first. Issues cached without history have none.
+func (d *DiskCache) GetIssueTransitions(key string) ([]history.Transition, error) {
+	cached, err := d.GetIssue(key)
+	if err != nil {
+		return nil, err
+	}
+	return history.Transitions(cached.JiraData), nil
+}
+
+// readIssueFile reads and unmarshals an issue file. Callers reading by key
+// or ID should hold the corresponding read lock.
 func (d *DiskCache) readIssueFile(path string) (*models.CachedIssue, error) {
+	data, err := readIssueBytes(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, err := d.unmarshalRecord(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal issue: %w", err)
+	}
+
+	return cached, nil
+}
+
+// readIssueBytes reads the stored bytes of an issue file
+func readIssueBytes(path string) ([]byte, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -138,13 +349,34 @@ func (d *DiskCache) readIssueFile(path string) (*models.CachedIssue, error) {
 		}
 		return nil, fmt.Errorf("failed to read issue file: %w", err)
 	}
+	return data, nil
+}
 
-	var cached models.CachedIssue
-	if err := json.Unmarshal(data, &cached); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal issue: %w", err)
+// GetIssueRaw returns the stored JSON of a cached issue (the CachedIssue
+// record, metadata included) without decoding it, e.g. to serve it as is.
+// Records with an interned changelog are expanded first.
+func (d *DiskCache) GetIssueRaw(key string) ([]byte, error) {
+	unlock := d.locks.rlock("key:" + key)
+	defer unlock()
+
+	path, ok := d.issuePath(key)
+	if !ok {
+		return nil, fmt.Errorf("issue not found in cache")
+	}
+	data, err := readIssueBytes(path)
+	if err != nil || !isInterned(data) {
+		return data, err
 	}
 
-	return &cached, nil
+	cached, err := d.unmarshalRecord(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal issue: %w", err)
+	}
+	data, err = json.Marshal(cached)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal issue: %w", err)
+	}
+	return data, nil
 }
 
 // GetLastFetched returns when an issue was last fetched (uses file mtime as fallback)
@@ -156,9 +388,11 @@ func (d *DiskCache) GetLastFetched(key string) (time.Time, error) {
 	}
 
 	// Fall back to file modification time
-	dataPath := d.getDataPath()
-	keyPath := filepath.Join(dataPath, "by_key", key+".json")
-	info, err := os.Stat(keyPath)
+	path, ok := d.issuePath(key)
+	if !ok {
+		return time.Time{}, fmt.Errorf("issue not found in cache")
+	}
+	info, err := os.Stat(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return time.Time{}, fmt.Errorf("issue not found in cache")
@@ -171,41 +405,57 @@ func (d *DiskCache) GetLastFetched(key string) (time.Time, error) {
 
 // Exists checks if an issue exists in the cache
 func (d *DiskCache) Exists(key string) bool {
-	dataPath := d.getDataPath()
-	keyPath := filepath.Join(dataPath, "by_key", key+".json")
-	_, err := os.Stat(keyPath)
+	path, ok := d.issuePath(key)
+	if !ok {
+		return false
+	}
+	_, err := os.Stat(path)
 	return err == nil
 }
 
 // ListIssues returns all cached issue keys
 func (d *DiskCache) ListIssues() ([]string, error) {
-	dataPath := d.getDataPath()
-	keyDir := filepath.Join(dataPath, "by_key")
-	entries, err := os.ReadDir(keyDir)
+	return d.listKeys()
+}
+
+// ListIssuesFetchedAfter returns the keys of issues fetched after t. An
+// issue's index entry is written after its metadata is stamped, so entries
+// no newer than t are skipped without opening the issue; the rest are
+// checked against their FetchedAt, falling back to the entry time when it
+// is unset.
+func (d *DiskCache) ListIssuesFetchedAfter(t time.Time) ([]string, error) {
+	times, err := d.keyModTimes()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return []string{}, nil
-		}
-		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+		return nil, err
 	}
 
 	var keys []string
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
-			key := strings.TrimSuffix(entry.Name(), ".json")
+	for key, modTime := range times {
+		if !modTime.After(t) {
+			continue
+		}
+
+		cached, err := d.GetIssue(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		if fetched := cached.CacheMetadata.FetchedAt; fetched.IsZero() || fetched.After(t) {
 			keys = append(keys, key)
 		}
 	}
 
+	sort.Strings(keys)
 	return keys, nil
 }
 
-// ListIssuesForProject returns all cached issue keys for a specific project
+// ListIssuesForProject returns all cached issue keys for a specific project.
+// Old keys of issues moved out of the project are not included.
 func (d *DiskCache) ListIssuesForProject(project string) ([]string, error) {
 	allKeys, err := d.ListIssues()
 	if err != nil {
 		return nil, err
 	}
+	allKeys = d.dropAliases(allKeys)
 
 	var projectKeys []string
 	for _, key := range allKeys {
@@ -217,4 +467,44 @@ func (d *DiskCache) ListIssuesForProject(project string) ([]string, error) {
 	return projectKeys, nil
 }
 
+// ListProjects returns the sorted, distinct project keys of the cached
+// issues: the part of each issue key before its last "-". Keys without a
+// project prefix or a numeric issue number are skipped, as are the old
+// keys of moved issues.
+func (d *DiskCache) ListProjects() ([]string, error) {
+	keys, err := d.ListIssues()
+	if err != nil {
+		return nil, err
+	}
+	keys = d.dropAliases(keys)
+
+	seen := make(map[string]bool)
+	var projects []string
+	for _, key := range keys {
+		project, ok := projectOfKey(key)
+		if !ok || seen[project] {
+			continue
+		}
+		seen[project] = true
+		projects = append(projects, project)
+	}
+
+	sort.Strings(projects)
+	return projects, nil
+}
+
+// projectOfKey splits the project from an issue key such as "PROJ-123"
+func projectOfKey(key string) (string, bool) {
+	i := strings.LastIndex(key, "-")
+	if i <= 0 || i == len(key)-1 {
+		return "", false
+	}
+	for _, r := range key[i+1:] {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+	return key[:i], true
+}
+
 
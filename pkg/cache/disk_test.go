@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// newTestCache returns an initialized cache in a temporary directory
+func newTestCache(t *testing.T) *DiskCache {
+	t.Helper()
+	d := NewWithHost(t.TempDir(), "https://jira.example.com")
+	if err := d.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	return d
+}
+
+// testIssue returns a minimal issue with the given ID, key and summary
+func testIssue(id, key, summary string) *models.IssueWithHistory {
+	issue := &models.IssueWithHistory{}
+	issue.ID = id
+	issue.Key = key
+	issue.Fields = &models.IssueFields{Summary: summary}
+	return issue
+}
+
+func TestConcurrentWriteAndReadSameKey(t *testing.T) {
+	d := newTestCache(t)
+	if _, err := d.WriteIssue(testIssue("10001", "PROJ-1", "initial"), 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+
+	const writers, readers, rounds = 8, 8, 25
+	var wg sync.WaitGroup
+	errs := make(chan error, (writers+readers)*rounds)
+
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for r := 0; r < rounds; r++ {
+				summary := fmt.Sprintf("writer %d round %d", w, r)
+				if _, err := d.WriteIssue(testIssue("10001", "PROJ-1", summary), 0); err != nil {
+					errs <- fmt.Errorf("WriteIssue: %w", err)
+				}
+			}
+		}(w)
+	}
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				cached, err := d.GetIssue("PROJ-1")
+				if err != nil {
+					errs <- fmt.Errorf("GetIssue: %w", err)
+					continue
+				}
+				if cached.JiraData == nil || cached.JiraData.Key != "PROJ-1" || cached.JiraData.Fields == nil {
+					errs <- fmt.Errorf("GetIssue returned an incomplete record: %+v", cached)
+				}
+				if !d.Exists("PROJ-1") {
+					errs <- fmt.Errorf("Exists reported PROJ-1 missing")
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	keys, err := d.ListIssues()
+	if err != nil {
+		t.Fatalf("ListIssues: %v", err)
+	}
+	if len(keys) != 1 || keys[0] != "PROJ-1" {
+		t.Errorf("ListIssues = %v, want [PROJ-1]", keys)
+	}
+}
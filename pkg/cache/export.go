@@ -0,0 +1,244 @@
+package cache
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// DefaultCSVColumns are the columns exported when none are requested
+var DefaultCSVColumns = []string{"key", "summary", "status", "assignee", "created", "updated", "resolutiondate"}
+
+// csvColumns maps column names to functions projecting an issue to a cell.
+// Nested objects are rendered by name; nil values become empty cells.
+var csvColumns = map[string]func(issue *models.IssueWithHistory) string{
+	"id":  func(issue *models.IssueWithHistory) string { return issue.ID },
+	"key": func(issue *models.IssueWithHistory) string { return issue.Key },
+	"summary": func(issue *models.IssueWithHistory) string {
+		return withFields(issue, func(f *models.IssueFields) string { return f.Summary })
+	},
+	"description": func(issue *models.IssueWithHistory) string {
+		return withFields(issue, func(f *models.IssueFields) string { return f.Description.PlainText() })
+	},
+	"issuetype": func(issue *models.IssueWithHistory) string {
+		return withFields(issue, func(f *models.IssueFields) string {
+			if f.IssueType == nil {
+				return ""
+			}
+			return f.IssueType.Name
+		})
+	},
+	"status": func(issue *models.IssueWithHistory) string {
+		return withFields(issue, func(f *models.IssueFields) string {
+			if f.Status == nil {
+				return ""
+			}
+			return f.Status.Name
+		})
+	},
+	"priority": func(issue *models.IssueWithHistory) string {
+		return withFields(issue, func(f *models.IssueFields) string {
+			if f.Priority == nil {
+				return ""
+			}
+			return f.Priority.Name
+		})
+	},
+	"assignee": func(issue *models.IssueWithHistory) string {
+		return withFields(issue, func(f *models.IssueFields) string { return userName(f.Assignee) })
+	},
+	"creator": func(issue *models.IssueWithHistory) string {
+		return withFields(issue, func(f *models.IssueFields) string { return userName(f.Creator) })
+	},
+	"created": func(issue *models.IssueWithHistory) string {
+		return withFields(issue, func(f *models.IssueFields) string { return f.Created })
+	},
+	"updated": func(issue *models.IssueWithHistory) string {
+		return withFields(issue, func(f *models.IssueFields) string { return f.Updated })
+	},
+	"labels": func(issue *models.IssueWithHistory) string {
+		return withFields(issue, func(f *models.IssueFields) string { return strings.Join(f.Labels, ";") })
+	},
+	"components": func(issue *models.IssueWithHistory) string {
+		return withFields(issue, func(f *models.IssueFields) string {
+			names := make([]string, 0, len(f.Components))
+			for _, component := range f.Components {
+				names = append(names, component.Name)
+			}
+			return strings.Join(names, ";")
+		})
+	},
+	"fixversions": func(issue *models.IssueWithHistory) string {
+		return withFields(issue, func(f *models.IssueFields) string { return versionNames(f.FixVersions) })
+	},
+	"versions": func(issue *models.IssueWithHistory) string {
+		return withFields(issue, func(f *models.IssueFields) string { return versionNames(f.AffectsVersions) })
+	},
+	"resolutiondate": func(issue *models.IssueWithHistory) string {
+		return withFields(issue, func(f *models.IssueFields) string {
+			if f.ResolutionDate == nil {
+				return ""
+			}
+			return *f.ResolutionDate
+		})
+	},
+}
+
+// withFields applies fn to the issue fields, returning "" if they are absent
+func withFields(issue *models.IssueWithHistory, fn func(f *models.IssueFields) string) string {
+	if issue.Fields == nil {
+		return ""
+	}
+	return fn(issue.Fields)
+}
+
+// versionNames renders a list of versions as semicolon-separated names
+func versionNames(versions []models.Version) string {
+	names := make([]string, 0, len(versions))
+	for _, version := range versions {
+		names = append(names, version.Name)
+	}
+	return strings.Join(names, ";")
+}
+
+// userName renders a user by display name, falling back to the username
+// and then the account identifier
+func userName(user *models.User) string {
+	if user == nil {
+		return ""
+	}
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	if user.Name != "" {
+		return user.Name
+	}
+	return user.Identifier()
+}
+
+// ExportCSV writes all cached issues as CSV rows with the requested columns.
+// A header row naming the columns is written first. If columns is empty,
+// DefaultCSVColumns is used.
+func (d *DiskCache) ExportCSV(w io.Writer, columns []string) error {
+	if len(columns) == 0 {
+		columns = DefaultCSVColumns
+	}
+
+	projections := make([]func(issue *models.IssueWithHistory) string, len(columns))
+	for i, column := range columns {
+		projection, ok := csvColumns[column]
+		if !ok {
+			return fmt.Errorf("unknown CSV column %q", column)
+		}
+		projections[i] = projection
+	}
+
+	keys, err := d.ListIssues()
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	row := make([]string, len(columns))
+	for _, key := range keys {
+		cached, err := d.GetIssue(key)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		if cached.JiraData == nil || cached.JiraData.Key != key {
+			// Skip empty records and alias links (e.g. moved issues)
+			continue
+		}
+
+		for i, projection := range projections {
+			row[i] = projection(cached.JiraData)
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row for %s: %w", key, err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ExportJSONL writes cached issues as JSON Lines, one CachedIssue per line.
+// If since is non-zero, only issues fetched after since are included, so
+// downstream systems can consume deltas. It returns the number of records
+// written.
+func (d *DiskCache) ExportJSONL(w io.Writer, since time.Time) (int, error) {
+	var keys []string
+	var err error
+	if since.IsZero() {
+		keys, err = d.ListIssues()
+	} else {
+		keys, err = d.ListIssuesFetchedAfter(since)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	encoder := json.NewEncoder(w)
+	written := 0
+	for _, key := range keys {
+		cached, err := d.GetIssue(key)
+		if err != nil {
+			return written, fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		if cached.JiraData != nil && cached.JiraData.Key != key {
+			// Alias link of a moved issue, exported under its current key
+			continue
+		}
+		if err := encoder.Encode(cached); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", key, err)
+		}
+		written++
+	}
+
+	return written, nil
+}
+
+// exportState records when a named incremental export last ran
+type exportState struct {
+	Name       string    `json:"name"`
+	LastExport time.Time `json:"last_export"`
+	Records    int       `json:"records"`
+}
+
+// ExportJSONLIncremental exports issues fetched since the previous run of
+// the export with the same name, then records this run's start time so
+// repeated exports chain without gaps. The first run exports everything.
+func (d *DiskCache) ExportJSONLIncremental(w io.Writer, name string) (int, error) {
+	metaName := "export-" + name
+
+	var state exportState
+	if err := d.ReadMeta(metaName, &state); err != nil {
+		state = exportState{Name: name}
+	}
+
+	// Use the start time so records written during the export are picked
+	// up by the next run
+	started := time.Now().UTC()
+
+	written, err := d.ExportJSONL(w, state.LastExport)
+	if err != nil {
+		return written, err
+	}
+
+	state.LastExport = started
+	state.Records = written
+	if err := d.WriteMeta(metaName, state); err != nil {
+		return written, fmt.Errorf("failed to record export time: %w", err)
+	}
+
+	return written, nil
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// newCacheWithMovedIssue returns a cache holding OTHER-2 and an issue moved
+// from OLD-1 to NEW-5, which stays reachable under its old key
+func newCacheWithMovedIssue(t *testing.T) *DiskCache {
+	t.Helper()
+	d := newTestCache(t)
+	if _, err := d.WriteIssue(testIssue("10002", "OTHER-2", "other"), 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+	meta := models.CacheMetadata{Renamed: &models.RenameInfo{FromKey: "OLD-1", ToKey: "NEW-5", DetectedAt: time.Now()}}
+	if _, err := d.WriteIssueWithMetadata(testIssue("10001", "NEW-5", "moved"), meta); err != nil {
+		t.Fatalf("WriteIssueWithMetadata: %v", err)
+	}
+	if _, err := d.GetIssue("OLD-1"); err != nil {
+		t.Fatalf("old key no longer resolves: %v", err)
+	}
+	return d
+}
+
+func TestExportsSkipMovedIssueAliases(t *testing.T) {
+	d := newCacheWithMovedIssue(t)
+
+	var csvOut bytes.Buffer
+	if err := d.ExportCSV(&csvOut, []string{"key"}); err != nil {
+		t.Fatalf("ExportCSV: %v", err)
+	}
+	rows, err := csv.NewReader(&csvOut).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"key"}, {"NEW-5"}, {"OTHER-2"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("CSV rows = %v, want %v", rows, want)
+	}
+
+	var jsonOut bytes.Buffer
+	written, err := d.ExportJSONL(&jsonOut, time.Time{})
+	if err != nil {
+		t.Fatalf("ExportJSONL: %v", err)
+	}
+	if lines := bytes.Count(jsonOut.Bytes(), []byte("\n")); written != 2 || lines != 2 {
+		t.Errorf("ExportJSONL wrote %d records in %d lines, want 2", written, lines)
+	}
+
+	var incremental bytes.Buffer
+	if written, err := d.ExportJSONLIncremental(&incremental, "test"); err != nil || written != 2 {
+		t.Errorf("ExportJSONLIncremental = %d, %v, want 2 records", written, err)
+	}
+}
+
+func TestProjectListingsSkipMovedIssueAliases(t *testing.T) {
+	d := newCacheWithMovedIssue(t)
+
+	projects, err := d.ListProjects()
+	if err != nil {
+		t.Fatalf("ListProjects: %v", err)
+	}
+	if want := []string{"NEW", "OTHER"}; !reflect.DeepEqual(projects, want) {
+		t.Errorf("ListProjects = %v, want %v", projects, want)
+	}
+
+	old, err := d.ListIssuesForProject("OLD")
+	if err != nil {
+		t.Fatalf("ListIssuesForProject: %v", err)
+	}
+	if len(old) != 0 {
+		t.Errorf("ListIssuesForProject(OLD) = %v, want none", old)
+	}
+	moved, err := d.ListIssuesForProject("NEW")
+	if err != nil {
+		t.Fatalf("ListIssuesForProject: %v", err)
+	}
+	if want := []string{"NEW-5"}; !reflect.DeepEqual(moved, want) {
+		t.Errorf("ListIssuesForProject(NEW) = %v, want %v", moved, want)
+	}
+}
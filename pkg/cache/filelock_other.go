@@ -0,0 +1,9 @@
+//go:build !unix
+
+package cache
+
+// lockFile is a no-op where advisory file locks are unavailable; writers in
+// separate processes are not coordinated on these platforms
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// keyFilename escapes an issue key for use as a file name. Uppercase
+// letters, digits, '-' and '_' are kept as-is, as is '.' anywhere but the
+// start; every other byte, lowercase letters included, becomes %XX. Issue
+// keys are uppercase, so standard keys like PROJ-123 are unchanged and
+// existing caches stay readable, while keys differing only in case still
+// get distinct files on case-insensitive filesystems.
+func keyFilename(key string) string {
+	return escapeFilename(key, true)
+}
+
+// safeFilename escapes a name for use as a file name like keyFilename, but
+// keeps lowercase letters, for names where case collisions do not matter
+func safeFilename(name string) string {
+	return escapeFilename(name, false)
+}
+
+// escapeFilename implements keyFilename and safeFilename. Names reserved
+// for devices on Windows (CON, NUL, COM1, ...), with or without an
+// extension, get their first byte escaped.
+func escapeFilename(name string, foldCase bool) string {
+	var b strings.Builder
+	reserved := isReservedName(name)
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		keep := isFilenameSafe(c) || (c == '.' && i > 0)
+		if foldCase && c >= 'a' && c <= 'z' {
+			keep = false
+		}
+		if i == 0 && reserved {
+			keep = false
+		}
+		if keep {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// isReservedName reports whether name, ignoring case and any extension, is
+// a Windows device name
+func isReservedName(name string) bool {
+	base, _, _ := strings.Cut(strings.ToUpper(name), ".")
+	switch base {
+	case "CON", "PRN", "AUX", "NUL":
+		return true
+	}
+	if len(base) == 4 && (strings.HasPrefix(base, "COM") || strings.HasPrefix(base, "LPT")) {
+		return base[3] >= '1' && base[3] <= '9'
+	}
+	return false
+}
+
+// keyFromFilename reverses keyFilename
+func keyFromFilename(name string) (string, error) {
+	if !strings.Contains(name, "%") {
+		return name, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] != '%' {
+			b.WriteByte(name[i])
+			continue
+		}
+		if i+2 >= len(name) {
+			return "", fmt.Errorf("truncated escape in %q", name)
+		}
+		c, err := strconv.ParseUint(name[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", fmt.Errorf("invalid escape in %q", name)
+		}
+		b.WriteByte(byte(c))
+		i += 2
+	}
+	return b.String(), nil
+}
+
+// isFilenameSafe reports whether c can appear unescaped in a file name on
+// any common filesystem
+func isFilenameSafe(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
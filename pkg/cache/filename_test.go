@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyFilename(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"PROJ-123", "PROJ-123"},
+		{"MY_PROJ-1", "MY_PROJ-1"},
+		{"A2B-7", "A2B-7"},
+		// Lowercase letters are escaped so case-only differences survive
+		// case-insensitive filesystems
+		{"proj-1", "%70%72%6F%6A-1"},
+		{"Proj-1", "P%72%6F%6A-1"},
+		// Path separators and traversal
+		{"PROJ/1", "PROJ%2F1"},
+		{`PROJ\1`, "PROJ%5C1"},
+		{"../PROJ-1", "%2E.%2FPROJ-1"},
+		{".", "%2E"},
+		{"", ""},
+		// Windows device names, with or without an extension
+		{"CON", "%43ON"},
+		{"NUL", "%4EUL"},
+		{"COM1", "%43OM1"},
+		{"LPT9.TXT", "%4CPT9.TXT"},
+		{"COM0", "COM0"},
+		{"CONSOLE", "CONSOLE"},
+		// Unicode is escaped byte by byte
+		{"ÄPFEL-1", "%C3%84PFEL-1"},
+		{"PROJ-١", "PROJ-%D9%A1"},
+		// The escape character itself
+		{"PROJ%41-1", "PROJ%2541-1"},
+	}
+
+	for _, tt := range tests {
+		got := keyFilename(tt.key)
+		if got != tt.want {
+			t.Errorf("keyFilename(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+		back, err := keyFromFilename(got)
+		if err != nil || back != tt.key {
+			t.Errorf("keyFromFilename(%q) = %q, %v, want %q", got, back, err, tt.key)
+		}
+		if strings.ContainsAny(got, `/\:*?"<>|`) {
+			t.Errorf("keyFilename(%q) = %q contains a path or reserved character", tt.key, got)
+		}
+	}
+}
+
+func TestKeyFilenameCaseCollisions(t *testing.T) {
+	keys := []string{"PROJ-1", "proj-1", "Proj-1", "pROJ-1"}
+	seen := map[string]string{}
+	for _, key := range keys {
+		folded := strings.ToLower(keyFilename(key))
+		if other, ok := seen[folded]; ok {
+			t.Errorf("%q and %q collide on a case-insensitive filesystem as %q", key, other, folded)
+		}
+		seen[folded] = key
+	}
+}
+
+func TestSafeFilenameKeepsLowercase(t *testing.T) {
+	if got := safeFilename("report.pdf"); got != "report.pdf" {
+		t.Errorf("safeFilename(report.pdf) = %q", got)
+	}
+	if got := safeFilename("nul.txt"); got != "%6Eul.txt" {
+		t.Errorf("safeFilename(nul.txt) = %q, want the device name escaped", got)
+	}
+}
+
+func TestEdgeCaseKeysRoundTripThroughCache(t *testing.T) {
+	d := newTestCache(t)
+	keys := []string{"PROJ-1", "proj-1", "CON", "A/B-2", "ÄPFEL-3"}
+	for i, key := range keys {
+		if _, err := d.WriteIssue(testIssue(string(rune('1'+i)), key, key), 0); err != nil {
+			t.Fatalf("WriteIssue(%q): %v", key, err)
+		}
+	}
+
+	for _, key := range keys {
+		cached, err := d.GetIssue(key)
+		if err != nil {
+			t.Errorf("GetIssue(%q): %v", key, err)
+			continue
+		}
+		if cached.JiraData.Key != key {
+			t.Errorf("GetIssue(%q) returned %q", key, cached.JiraData.Key)
+		}
+	}
+
+	listed, err := d.ListIssues()
+	if err != nil {
+		t.Fatalf("ListIssues: %v", err)
+	}
+	if len(listed) != len(keys) {
+		t.Errorf("ListIssues = %q, want %d keys", listed, len(keys))
+	}
+
+	// Every by_key entry stays inside by_key
+	entries, err := os.ReadDir(filepath.Join(d.getDataPath(), "by_key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(keys) {
+		t.Errorf("by_key has %d entries, want %d", len(entries), len(keys))
+	}
+}
@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// assigneeIndexDir returns the directory of the assignee reverse index
+// Format: .data/jira/<hostname>/index/by_assignee/<name>.json
+func (d *DiskCache) assigneeIndexDir() string {
+	return filepath.Join(d.getDataPath(), "index", "by_assignee")
+}
+
+// assigneeIndexPath returns the index file for an assignee name
+func (d *DiskCache) assigneeIndexPath(name string) string {
+	return filepath.Join(d.assigneeIndexDir(), url.PathEscape(name)+".json")
+}
+
+// assigneeName returns the name an issue is indexed under: the user name on
+// JIRA Server/Data Center, or the account ID on JIRA Cloud. Unassigned
+// issues return "".
+func assigneeName(issue *models.IssueWithHistory) string {
+	if issue == nil || issue.Fields == nil || issue.Fields.Assignee == nil {
+		return ""
+	}
+	if issue.Fields.Assignee.Name != "" {
+		return issue.Fields.Assignee.Name
+	}
+	return issue.Fields.Assignee.AccountID
+}
+
+// GetIssuesByAssignee returns the sorted keys of cached issues assigned to
+// the named user (user name, or account ID on JIRA Cloud)
+func (d *DiskCache) GetIssuesByAssignee(name string) ([]string, error) {
+	d.indexMu.Lock()
+	defer d.indexMu.Unlock()
+	return d.readAssigneeIndex(name)
+}
+
+// readAssigneeIndex reads an assignee's index file; callers hold indexMu
+func (d *DiskCache) readAssigneeIndex(name string) ([]string, error) {
+	data, err := os.ReadFile(d.assigneeIndexPath(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read assignee index: %w", err)
+	}
+
+	var keys []string
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal assignee index: %w", err)
+	}
+	return keys, nil
+}
+
+// writeAssigneeIndex replaces an assignee's index file, removing it when
+// keys is empty; callers hold indexMu
+func (d *DiskCache) writeAssigneeIndex(name string, keys []string) error {
+	path := d.assigneeIndexPath(name)
+	if len(keys) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove assignee index: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+
+	sort.Strings(keys)
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal assignee index: %w", err)
+	}
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write assignee index: %w", err)
+	}
+	return nil
+}
+
+// updateAssigneeIndex moves an issue from its previous entry (oldKey under
+// oldName, either may be empty) to key under newName
+func (d *DiskCache) updateAssigneeIndex(oldKey, oldName, key, newName string) error {
+	if oldKey == key && oldName == newName {
+		return nil
+	}
+
+	d.indexMu.Lock()
+	defer d.indexMu.Unlock()
+
+	if oldName != "" && oldKey != "" {
+		keys, err := d.readAssigneeIndex(oldName)
+		if err != nil {
+			return err
+		}
+		kept := keys[:0]
+		for _, k := range keys {
+			if k != oldKey {
+				kept = append(kept, k)
+			}
+		}
+		if err := d.writeAssigneeIndex(oldName, kept); err != nil {
+			return err
+		}
+	}
+
+	if newName != "" {
+		keys, err := d.readAssigneeIndex(newName)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if k == key {
+				return nil
+			}
+		}
+		if err := d.writeAssigneeIndex(newName, append(keys, key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reindex rebuilds the assignee index from the cached issues, e.g. after
+// upgrading a cache written before the index existed. It returns the number
+// of issues indexed.
+func (d *DiskCache) Reindex() (int, error) {
+	d.indexMu.Lock()
+	defer d.indexMu.Unlock()
+
+	keys, err := d.listKeys()
+	if err != nil {
+		return 0, err
+	}
+
+	byAssignee := map[string][]string{}
+	indexed := 0
+	for _, key := range keys {
+		path, ok := d.issuePath(key)
+		if !ok {
+			continue
+		}
+
+		// Writes are atomic, so reading without the issue locks is safe
+		cached, err := d.readIssueFile(path)
+		if err != nil {
+			log.Printf("Warning: skipping %s while reindexing: %v", key, err)
+			continue
+		}
+		if cached.JiraData == nil || cached.JiraData.Key != key {
+			// Alias links for moved issues are indexed under the current key
+			continue
+		}
+
+		if name := assigneeName(cached.JiraData); name != "" {
+			byAssignee[name] = append(byAssignee[name], key)
+		}
+		indexed++
+	}
+
+	if err := os.RemoveAll(d.assigneeIndexDir()); err != nil {
+		return 0, fmt.Errorf("failed to clear assignee index: %w", err)
+	}
+	for name, keys := range byAssignee {
+		if err := d.writeAssigneeIndex(name, keys); err != nil {
+			return 0, err
+		}
+	}
+
+	log.Printf("Reindexed %d issues (%d assignees)", indexed, len(byAssignee))
+	return indexed, nil
+}
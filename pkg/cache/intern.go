@@ -0,0 +1,465 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// Changelog interning stores each issue's changelog with its repeated
+// strings (field names, short field values such as statuses, authors)
+// replaced by references into a dictionary shared by the whole cache:
+//
+//	.data/jira/<hostname>/dictionary.jsonl
+//
+// The dictionary is an append-only log with one JSON string per line, so
+// references never change meaning and new entries cost one append. Entries
+// are logged before any record using them is written. Free text (long or
+// multi-line values and the text fields in freeTextFields) is stored inline,
+// keeping the dictionary to low-cardinality strings.
+//
+// Reads expand interned records transparently whether or not interning is
+// enabled, so it can be turned on or off for an existing cache. Appends
+// are serialized across processes by an advisory lock on
+// dictionary.jsonl.lock, and a reader that meets a reference beyond its
+// copy of the dictionary rereads the new entries.
+
+// internedField is the record field holding an interned changelog, in
+// place of jira_data.changelog
+const internedField = "_interned_changelog"
+
+// maxInternedLength is the longest field value that is interned
+const maxInternedLength = 64
+
+// freeTextFields are changelog fields whose values are stored inline
+// regardless of length
+var freeTextFields = map[string]bool{
+	"summary":     true,
+	"description": true,
+	"environment": true,
+	"comment":     true,
+}
+
+// stringDict is the cache-wide string dictionary. Reference n is
+// strings[n-1].
+type stringDict struct {
+	mu      sync.Mutex
+	loaded  bool
+	size    int64 // Bytes of the log read so far
+	strings []string
+	refs    map[string]int
+}
+
+// internedValue is a changelog value in an interned record: a reference
+// into the dictionary (a JSON number), an inline string, or null
+type internedValue struct {
+	ref    int
+	inline *string
+}
+
+func (v internedValue) MarshalJSON() ([]byte, error) {
+	if v.ref > 0 {
+		return json.Marshal(v.ref)
+	}
+	return json.Marshal(v.inline)
+}
+
+func (v *internedValue) UnmarshalJSON(data []byte) error {
+	*v = internedValue{}
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &v.inline)
+	}
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	return json.Unmarshal(data, &v.ref)
+}
+
+// internedChangelog is the stored form of an interned changelog
+type internedChangelog struct {
+	StartAt    int               `json:"startAt"`
+	MaxResults int               `json:"maxResults"`
+	Total      int               `json:"total"`
+	Histories  []internedHistory `json:"histories"`
+}
+
+// internedHistory is the stored form of a history entry. Author refers to
+// the author's JSON encoding, so each distinct user is stored once; each
+// item is [field, fieldtype, from, fromString, to, toString].
+type internedHistory struct {
+	ID      string             `json:"id"`
+	Author  int                `json:"author,omitempty"`
+	Created string             `json:"created"`
+	Items   [][6]internedValue `json:"items"`
+}
+
+// internedRecord is a cache record whose changelog is interned
+type internedRecord struct {
+	*models.CachedIssue
+	Changelog *internedChangelog `json:"_interned_changelog,omitempty"`
+}
+
+// SetInternChangelogs enables storing changelogs with their repeated
+// strings interned in the cache's dictionary. Changelog-heavy caches
+// shrink considerably; records are expanded transparently on read.
+func (d *DiskCache) SetInternChangelogs(intern bool) {
+	d.intern = intern
+}
+
+// dictionaryPath returns the path of the string dictionary log
+func (d *DiskCache) dictionaryPath() string {
+	return filepath.Join(d.getDataPath(), "dictionary.jsonl")
+}
+
+// loadDictionary reads the dictionary log on first use. Callers hold
+// d.dict.mu.
+func (d *DiskCache) loadDictionary() error {
+	if d.dict.loaded {
+		return nil
+	}
+	return d.readDictionary(false)
+}
+
+// readDictionary reads entries appended to the log since the last read,
+// which other processes may have written. A partial last line is ignored,
+// or with truncate (only safe under the dictionary file lock), truncated
+// away as left by an interrupted append; no record can refer to it.
+// Callers hold d.dict.mu.
+func (d *DiskCache) readDictionary(truncate bool) error {
+	if d.dict.refs == nil {
+		d.dict.refs = make(map[string]int)
+	}
+	file, err := os.Open(d.dictionaryPath())
+	if os.IsNotExist(err) {
+		d.dict.loaded = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read dictionary: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(d.dict.size, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read dictionary: %w", err)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("failed to read dictionary: %w", err)
+	}
+	if complete := bytes.LastIndexByte(data, '\n') + 1; complete < len(data) {
+		if truncate {
+			if err := os.Truncate(d.dictionaryPath(), d.dict.size+int64(complete)); err != nil {
+				return fmt.Errorf("failed to truncate partial dictionary entry: %w", err)
+			}
+		}
+		data = data[:complete]
+	}
+
+	var entries []string
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(line, &s); err != nil {
+			return fmt.Errorf("failed to parse dictionary entry %d: %w", len(d.dict.strings)+len(entries)+1, err)
+		}
+		entries = append(entries, s)
+	}
+
+	for _, s := range entries {
+		d.dict.strings = append(d.dict.strings, s)
+		d.dict.refs[s] = len(d.dict.strings)
+	}
+	d.dict.size += int64(len(data))
+	d.dict.loaded = true
+	return nil
+}
+
+// addDictionary logs the entries not yet in the dictionary. The file lock
+// is held while catching up with other processes' appends and writing, so
+// each reference is assigned exactly once. Callers hold d.dict.mu.
+func (d *DiskCache) addDictionary(entries []string) error {
+	if err := os.MkdirAll(d.getDataPath(), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	unlock, err := lockFile(d.dictionaryPath() + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := d.readDictionary(true); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	var added []string
+	for _, s := range entries {
+		if _, ok := d.dict.refs[s]; ok {
+			continue
+		}
+		line, err := json.Marshal(s)
+		if err != nil {
+			return fmt.Errorf("failed to marshal dictionary entry: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+		added = append(added, s)
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	file, err := os.OpenFile(d.dictionaryPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open dictionary: %w", err)
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write dictionary: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write dictionary: %w", err)
+	}
+
+	for _, s := range added {
+		d.dict.strings = append(d.dict.strings, s)
+		d.dict.refs[s] = len(d.dict.strings)
+	}
+	d.dict.size += int64(buf.Len())
+	return nil
+}
+
+// internable reports whether a changelog value is stored as a dictionary
+// reference rather than inline
+func internable(s *string, freeText bool) bool {
+	return s != nil && !freeText && len(*s) <= maxInternedLength && !strings.Contains(*s, "\n")
+}
+
+// internChangelog converts a changelog to its interned form, logging new
+// dictionary entries before returning
+func (d *DiskCache) internChangelog(changelog *models.Changelog) (*internedChangelog, error) {
+	d.dict.mu.Lock()
+	defer d.dict.mu.Unlock()
+
+	if err := d.loadDictionary(); err != nil {
+		return nil, err
+	}
+
+	// Marshal authors once and log any strings the dictionary lacks
+	authors := make([]string, len(changelog.Histories))
+	var missing []string
+	seen := make(map[string]bool)
+	need := func(s string) {
+		if _, ok := d.dict.refs[s]; !ok && !seen[s] {
+			seen[s] = true
+			missing = append(missing, s)
+		}
+	}
+	needValue := func(s *string, freeText bool) {
+		if internable(s, freeText) {
+			need(*s)
+		}
+	}
+	for i, history := range changelog.Histories {
+		if history.Author != nil {
+			author, err := json.Marshal(history.Author)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal author: %w", err)
+			}
+			authors[i] = string(author)
+			need(authors[i])
+		}
+		for _, item := range history.Items {
+			freeText := freeTextFields[strings.ToLower(item.Field)]
+			needValue(&item.Field, false)
+			needValue(&item.FieldType, false)
+			needValue(item.From, freeText)
+			needValue(item.FromString, freeText)
+			needValue(item.To, freeText)
+			needValue(item.ToString, freeText)
+		}
+	}
+	if len(missing) > 0 {
+		if err := d.addDictionary(missing); err != nil {
+			return nil, err
+		}
+	}
+
+	value := func(s *string, freeText bool) internedValue {
+		if !internable(s, freeText) {
+			return internedValue{inline: s}
+		}
+		return internedValue{ref: d.dict.refs[*s]}
+	}
+
+	interned := &internedChangelog{
+		StartAt:    changelog.StartAt,
+		MaxResults: changelog.MaxResults,
+		Total:      changelog.Total,
+		Histories:  make([]internedHistory, len(changelog.Histories)),
+	}
+	for i, history := range changelog.Histories {
+		h := internedHistory{
+			ID:      history.ID,
+			Created: history.Created,
+			Items:   make([][6]internedValue, len(history.Items)),
+		}
+		if history.Author != nil {
+			h.Author = d.dict.refs[authors[i]]
+		}
+		for j, item := range history.Items {
+			field, fieldType := item.Field, item.FieldType
+			freeText := freeTextFields[strings.ToLower(field)]
+			h.Items[j] = [6]internedValue{
+				value(&field, false), value(&fieldType, false),
+				value(item.From, freeText), value(item.FromString, freeText),
+				value(item.To, freeText), value(item.ToString, freeText),
+			}
+		}
+		interned.Histories[i] = h
+	}
+	return interned, nil
+}
+
+// expandChangelog converts an interned changelog back to its full form
+func (d *DiskCache) expandChangelog(interned *internedChangelog) (*models.Changelog, error) {
+	d.dict.mu.Lock()
+	defer d.dict.mu.Unlock()
+
+	if err := d.loadDictionary(); err != nil {
+		return nil, err
+	}
+	// A reference past the end was logged by another process since the
+	// dictionary was read
+	if maxRef(interned) > len(d.dict.strings) {
+		if err := d.readDictionary(false); err != nil {
+			return nil, err
+		}
+	}
+
+	var badRef error
+	lookupRef := func(n int) *string {
+		if n == 0 {
+			return nil
+		}
+		if n < 0 || n > len(d.dict.strings) {
+			badRef = fmt.Errorf("dictionary reference %d out of range", n)
+			return nil
+		}
+		s := d.dict.strings[n-1]
+		return &s
+	}
+	lookup := func(v internedValue) *string {
+		if v.ref > 0 {
+			return lookupRef(v.ref)
+		}
+		return v.inline
+	}
+	text := func(v internedValue) string {
+		if s := lookup(v); s != nil {
+			return *s
+		}
+		return ""
+	}
+
+	changelog := &models.Changelog{
+		StartAt:    interned.StartAt,
+		MaxResults: interned.MaxResults,
+		Total:      interned.Total,
+		Histories:  make([]models.History, len(interned.Histories)),
+	}
+	for i, h := range interned.Histories {
+		history := models.History{
+			ID:      h.ID,
+			Created: h.Created,
+			Items:   make([]models.HistoryItem, len(h.Items)),
+		}
+		if author := lookupRef(h.Author); author != nil {
+			history.Author = &models.User{}
+			if err := json.Unmarshal([]byte(*author), history.Author); err != nil {
+				return nil, fmt.Errorf("failed to parse author: %w", err)
+			}
+		}
+		for j, item := range h.Items {
+			history.Items[j] = models.HistoryItem{
+				Field:      text(item[0]),
+				FieldType:  text(item[1]),
+				From:       lookup(item[2]),
+				FromString: lookup(item[3]),
+				To:         lookup(item[4]),
+				ToString:   lookup(item[5]),
+			}
+		}
+		changelog.Histories[i] = history
+	}
+	if badRef != nil {
+		return nil, badRef
+	}
+	return changelog, nil
+}
+
+// maxRef returns the highest dictionary reference in an interned changelog
+func maxRef(interned *internedChangelog) int {
+	highest := 0
+	for _, h := range interned.Histories {
+		highest = max(highest, h.Author)
+		for _, item := range h.Items {
+			for _, v := range item {
+				highest = max(highest, v.ref)
+			}
+		}
+	}
+	return highest
+}
+
+// internRecord returns the record to marshal for cached: cached itself, or
+// with interning enabled, a copy whose changelog is interned
+func (d *DiskCache) internRecord(cached *models.CachedIssue) (any, error) {
+	if !d.intern || cached.JiraData == nil || cached.JiraData.Changelog == nil {
+		return cached, nil
+	}
+
+	changelog, err := d.internChangelog(cached.JiraData.Changelog)
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy rather than modify the caller's issue
+	issue := *cached.JiraData
+	issue.Changelog = nil
+	return internedRecord{
+		CachedIssue: &models.CachedIssue{CacheMetadata: cached.CacheMetadata, JiraData: &issue},
+		Changelog:   changelog,
+	}, nil
+}
+
+// unmarshalRecord decodes a stored record, expanding an interned changelog
+func (d *DiskCache) unmarshalRecord(data []byte) (*models.CachedIssue, error) {
+	record := internedRecord{CachedIssue: &models.CachedIssue{}}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+
+	if record.Changelog != nil && record.JiraData != nil {
+		changelog, err := d.expandChangelog(record.Changelog)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand changelog: %w", err)
+		}
+		record.JiraData.Changelog = changelog
+	}
+	return record.CachedIssue, nil
+}
+
+// isInterned reports whether stored record bytes hold an interned changelog
+func isInterned(data []byte) bool {
+	return bytes.Contains(data, []byte(`"`+internedField+`"`))
+}
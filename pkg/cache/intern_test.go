@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+func strPtr(s string) *string { return &s }
+
+// changelogIssue returns an issue with a status change and a description
+// edit of the given text
+func changelogIssue(id, key, description string) *models.IssueWithHistory {
+	issue := testIssue(id, key, "summary "+key)
+	issue.Changelog = &models.Changelog{
+		Total: 2,
+		Histories: []models.History{
+			{
+				ID:      id + "-1",
+				Author:  &models.User{Name: "alice", DisplayName: "Alice"},
+				Created: "2024-01-02T10:00:00.000+0000",
+				Items: []models.HistoryItem{{
+					Field: "status", FieldType: "jira",
+					From: strPtr("1"), FromString: strPtr("Open"),
+					To: strPtr("3"), ToString: strPtr("In Progress"),
+				}},
+			},
+			{
+				ID:      id + "-2",
+				Author:  &models.User{Name: "alice", DisplayName: "Alice"},
+				Created: "2024-01-03T10:00:00.000+0000",
+				Items: []models.HistoryItem{{
+					Field: "description", FieldType: "jira",
+					ToString: strPtr(description),
+				}},
+			},
+		},
+	}
+	return issue
+}
+
+func TestInternedChangelogRoundTrip(t *testing.T) {
+	d := newTestCache(t)
+	d.SetInternChangelogs(true)
+
+	issue := changelogIssue("10001", "PROJ-1", "short")
+	if _, err := d.WriteIssue(issue, 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+
+	// A fresh cache reads the dictionary back from disk
+	reopened := NewWithHost(d.baseDir, "https://jira.example.com")
+	cached, err := reopened.GetIssue("PROJ-1")
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	if !reflect.DeepEqual(cached.JiraData.Changelog, issue.Changelog) {
+		t.Errorf("changelog = %+v, want %+v", cached.JiraData.Changelog, issue.Changelog)
+	}
+}
+
+func TestInternedDictionaryExcludesFreeText(t *testing.T) {
+	d := newTestCache(t)
+	d.SetInternChangelogs(true)
+
+	for i, id := range []string{"10001", "10002", "10003"} {
+		key := "PROJ-" + id[len(id)-1:]
+		description := strings.Repeat("unique text ", i+1)
+		if _, err := d.WriteIssue(changelogIssue(id, key, description), 0); err != nil {
+			t.Fatalf("WriteIssue: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(d.dictionaryPath())
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if strings.Contains(string(data), "unique text") {
+		t.Errorf("dictionary holds free text:\n%s", data)
+	}
+	// status, description, jira, 1, Open, 3, In Progress and the author,
+	// each logged once across all three issues
+	if lines := strings.Count(string(data), "\n"); lines != 8 {
+		t.Errorf("dictionary has %d entries, want 8:\n%s", lines, data)
+	}
+}
+
+func TestInternedDictionaryTruncatesPartialEntry(t *testing.T) {
+	d := newTestCache(t)
+	d.SetInternChangelogs(true)
+	if _, err := d.WriteIssue(changelogIssue("10001", "PROJ-1", "text"), 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+
+	// Simulate an append interrupted mid-line
+	file, err := os.OpenFile(d.dictionaryPath(), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	file.WriteString(`"partial`)
+	file.Close()
+
+	reopened := NewWithHost(d.baseDir, "https://jira.example.com")
+	reopened.SetInternChangelogs(true)
+	if _, err := reopened.GetIssue("PROJ-1"); err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	// The next append, logging a new status, replaces the partial entry
+	issue := changelogIssue("10002", "PROJ-2", "text")
+	issue.Changelog.Histories[0].Items[0].ToString = strPtr("Closed")
+	if _, err := reopened.WriteIssue(issue, 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+
+	data, err := os.ReadFile(d.dictionaryPath())
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if strings.Contains(string(data), "partial") || !strings.HasSuffix(string(data), "\"Closed\"\n") {
+		t.Errorf("partial entry not truncated:\n%s", data)
+	}
+}
+
+func TestInternedDictionarySharedBetweenCaches(t *testing.T) {
+	// Two caches on one directory stand in for two processes
+	first := newTestCache(t)
+	first.SetInternChangelogs(true)
+	second := NewWithHost(first.baseDir, "https://jira.example.com")
+	second.SetInternChangelogs(true)
+
+	if _, err := first.WriteIssue(changelogIssue("10001", "PROJ-1", "text"), 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+	// second loads the dictionary before first logs "Closed"
+	if _, err := second.GetIssue("PROJ-1"); err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+
+	closed := changelogIssue("10002", "PROJ-2", "text")
+	closed.Changelog.Histories[0].Items[0].ToString = strPtr("Closed")
+	if _, err := first.WriteIssue(closed, 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+	// second must not assign "Resolved" the reference first gave "Closed"
+	resolved := changelogIssue("10003", "PROJ-3", "text")
+	resolved.Changelog.Histories[0].Items[0].ToString = strPtr("Resolved")
+	if _, err := second.WriteIssue(resolved, 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+
+	for _, tt := range []struct {
+		cache *DiskCache
+		key   string
+		want  string
+	}{
+		{second, "PROJ-2", "Closed"},
+		{first, "PROJ-3", "Resolved"},
+		{first, "PROJ-2", "Closed"},
+	} {
+		cached, err := tt.cache.GetIssue(tt.key)
+		if err != nil {
+			t.Fatalf("GetIssue(%s): %v", tt.key, err)
+		}
+		if got := cached.JiraData.Changelog.Histories[0].Items[0].ToString; got == nil || *got != tt.want {
+			t.Errorf("%s status = %v, want %s", tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// IndexStrategy selects how issue keys are mapped to by_id files on disk.
+// Changing the strategy of an existing cache does not convert it; rewrite
+// the issues (e.g. with a full sync) after switching.
+type IndexStrategy string
+
+const (
+	// IndexSymlink links by_key/<key>.json to the by_id file (the default)
+	IndexSymlink IndexStrategy = "symlink"
+
+	// IndexKeyFile writes by_key/<key>.json as a small {"id": ...} pointer,
+	// for filesystems and backup tools that handle symlinks poorly
+	IndexKeyFile IndexStrategy = "keyfile"
+
+	// IndexNone stores only by_id files. Keys are resolved through an
+	// in-memory map built by reading every by_id file on first use.
+	IndexNone IndexStrategy = "none"
+)
+
+// keyPointer is the content of a by_key file under IndexKeyFile
+type keyPointer struct {
+	ID string `json:"id"`
+}
+
+// SetIndexStrategy selects how keys are indexed on disk (default
+// IndexSymlink)
+func (d *DiskCache) SetIndexStrategy(strategy IndexStrategy) error {
+	switch strategy {
+	case IndexSymlink, IndexKeyFile, IndexNone:
+	default:
+		return fmt.Errorf("unknown index strategy %q", strategy)
+	}
+	if strategy != IndexSymlink && d.layout != "" && d.layout != LayoutByID {
+		return fmt.Errorf("the %s layout requires the %q index strategy", d.layout, IndexSymlink)
+	}
+
+	d.keyMapMu.Lock()
+	d.indexStrategy = strategy
+	d.keyMap = nil
+	d.keyMapMu.Unlock()
+	return nil
+}
+
+// keyPath returns the by_key entry for an issue key, escaped with
+// keyFilename
+func (d *DiskCache) keyPath(key string) string {
+	return filepath.Join(d.getDataPath(), "by_key", keyFilename(key)+".json")
+}
+
+// keyEntry returns the issue key for a by_key directory entry, or false if
+// the entry is not an index entry
+func keyEntry(name string) (string, bool) {
+	if !strings.HasSuffix(name, ".json") {
+		return "", false
+	}
+	key, err := keyFromFilename(strings.TrimSuffix(name, ".json"))
+	if err != nil {
+		log.Printf("Warning: skipping by_key entry %s: %v", name, err)
+		return "", false
+	}
+	return key, true
+}
+
+// idPath returns the by_id file for an issue ID
+func (d *DiskCache) idPath(id string) string {
+	return filepath.Join(d.getDataPath(), "by_id", id+".json")
+}
+
+// writeKeyIndex records that key resolves to the issue with the given ID
+func (d *DiskCache) writeKeyIndex(key, id string) error {
+	switch d.indexStrategy {
+	case IndexKeyFile:
+		data, err := json.Marshal(keyPointer{ID: id})
+		if err != nil {
+			return err
+		}
+		return writeFileAtomic(d.keyPath(key), data, 0644)
+	case IndexNone:
+		d.keyMapMu.Lock()
+		if d.keyMap != nil {
+			d.keyMap[key] = id
+		}
+		d.keyMapMu.Unlock()
+		return nil
+	default:
+		return symlinkAtomic(filepath.Join("..", "by_id", id+".json"), d.keyPath(key))
+	}
+}
+
+// removeKeyIndex removes key from the index; a missing entry is not an error
+func (d *DiskCache) removeKeyIndex(key string) error {
+	if d.indexStrategy == IndexNone {
+		d.keyMapMu.Lock()
+		delete(d.keyMap, key)
+		d.keyMapMu.Unlock()
+		return nil
+	}
+	if err := os.Remove(d.keyPath(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove key index entry: %w", err)
+	}
+	return nil
+}
+
+// issuePath returns the file to read for key. It returns false when the
+// key is known not to be cached; otherwise the file may still be missing.
+func (d *DiskCache) issuePath(key string) (string, bool) {
+	switch d.indexStrategy {
+	case IndexKeyFile:
+		data, err := os.ReadFile(d.keyPath(key))
+		if err != nil {
+			return "", false
+		}
+		var pointer keyPointer
+		if err := json.Unmarshal(data, &pointer); err != nil || pointer.ID == "" {
+			return "", false
+		}
+		return d.idPath(pointer.ID), true
+	case IndexNone:
+		id, ok := d.loadKeyMap()[key]
+		if !ok {
+			return "", false
+		}
+		return d.idPath(id), true
+	default:
+		return d.keyPath(key), true
+	}
+}
+
+// loadKeyMap returns the key to ID map used by IndexNone, building it from
+// the by_id files on first use. The returned map must not be modified.
+func (d *DiskCache) loadKeyMap() map[string]string {
+	d.keyMapMu.Lock()
+	defer d.keyMapMu.Unlock()
+	if d.keyMap != nil {
+		return d.keyMap
+	}
+
+	keyMap := map[string]string{}
+	idDir := filepath.Join(d.getDataPath(), "by_id")
+	entries, err := os.ReadDir(idDir)
+	if err != nil && !os.IsNotExist(err) {
+		log.Printf("Warning: failed to read %s: %v", idDir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		id := strings.TrimSuffix(entry.Name(), ".json")
+		cached, err := d.readIssueFile(filepath.Join(idDir, entry.Name()))
+		if err != nil || cached.JiraData == nil {
+			continue
+		}
+		keyMap[cached.JiraData.Key] = id
+		if renamed := cached.CacheMetadata.Renamed; renamed != nil && renamed.FromKey != "" {
+			if _, taken := keyMap[renamed.FromKey]; !taken {
+				keyMap[renamed.FromKey] = id
+			}
+		}
+	}
+
+	d.keyMap = keyMap
+	return keyMap
+}
+
+// listKeys returns all indexed keys, including aliases of moved issues
+func (d *DiskCache) listKeys() ([]string, error) {
+	if d.indexStrategy == IndexNone {
+		keyMap := d.loadKeyMap()
+		d.keyMapMu.Lock()
+		keys := make([]string, 0, len(keyMap))
+		for key := range keyMap {
+			keys = append(keys, key)
+		}
+		d.keyMapMu.Unlock()
+		sort.Strings(keys)
+		return keys, nil
+	}
+
+	entries, err := os.ReadDir(filepath.Join(d.getDataPath(), "by_key"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	var keys []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if key, ok := keyEntry(entry.Name()); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// keyModTimes returns the time each key's index entry (or, under IndexNone,
+// its by_id file) was last written. Every write of an issue rewrites these,
+// so they approximate the fetch time without opening the issue files.
+func (d *DiskCache) keyModTimes() (map[string]time.Time, error) {
+	times := map[string]time.Time{}
+
+	if d.indexStrategy == IndexNone {
+		keys, err := d.listKeys()
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			path, ok := d.issuePath(key)
+			if !ok {
+				continue
+			}
+			if info, err := os.Stat(path); err == nil {
+				times[key] = info.ModTime()
+			}
+		}
+		return times, nil
+	}
+
+	entries, err := os.ReadDir(filepath.Join(d.getDataPath(), "by_key"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return times, nil
+		}
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		key, ok := keyEntry(entry.Name())
+		if !ok {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			times[key] = info.ModTime()
+		}
+	}
+	return times, nil
+}
+
+// dropAliases removes the old keys of moved issues from keys. An alias
+// shares its record with the issue's current key, so only records reached
+// through more than one key are read. An alias whose current key is not
+// indexed is kept.
+func (d *DiskCache) dropAliases(keys []string) []string {
+	byRecord := make(map[string][]string, len(keys))
+	for _, key := range keys {
+		path, ok := d.issuePath(key)
+		if !ok {
+			continue
+		}
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			path = resolved
+		}
+		byRecord[path] = append(byRecord[path], key)
+	}
+
+	aliases := make(map[string]bool)
+	for path, shared := range byRecord {
+		if len(shared) < 2 {
+			continue
+		}
+		cached, err := d.readIssueFile(path)
+		if err != nil || cached.JiraData == nil {
+			continue
+		}
+		for _, key := range shared {
+			if key != cached.JiraData.Key {
+				aliases[key] = true
+			}
+		}
+	}
+
+	kept := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !aliases[key] {
+			kept = append(kept, key)
+		}
+	}
+	return kept
+}
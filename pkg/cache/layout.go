@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// Layout selects where issue records are stored under the data path.
+// Changing the layout of an existing cache does not convert it; rewrite the
+// issues (e.g. with a full sync) after switching.
+type Layout string
+
+const (
+	// LayoutByID stores records as by_id/<id>.json, indexed by key under
+	// by_key (the default)
+	LayoutByID Layout = "by_id"
+
+	// LayoutFlat stores records directly as by_key/<key>.json. Keys an
+	// issue was moved from are symlinks to its current record.
+	LayoutFlat Layout = "flat"
+
+	// LayoutDated stores records as <YYYY>/<MM>/<DD>/<key>.json by the
+	// issue's creation date (UTC), or undated/<key>.json when it is
+	// unknown, with by_key symlinks for lookups
+	LayoutDated Layout = "dated"
+)
+
+// SetLayout selects the on-disk layout of issue records (default
+// LayoutByID). The flat and dated layouts index keys with symlinks, so
+// they require the default IndexSymlink strategy, and do not support
+// GetIssueByID.
+func (d *DiskCache) SetLayout(layout Layout) error {
+	switch layout {
+	case LayoutByID:
+	case LayoutFlat, LayoutDated:
+		if d.indexStrategy != "" && d.indexStrategy != IndexSymlink {
+			return fmt.Errorf("layout %q requires the %q index strategy", layout, IndexSymlink)
+		}
+	default:
+		return fmt.Errorf("unknown layout %q", layout)
+	}
+	d.layout = layout
+	return nil
+}
+
+// recordPath returns the file an issue's record is stored in
+func (d *DiskCache) recordPath(issue *models.IssueWithHistory) string {
+	name := keyFilename(issue.Key) + ".json"
+	switch d.layout {
+	case LayoutFlat:
+		return d.keyPath(issue.Key)
+	case LayoutDated:
+		if issue.Fields != nil {
+			if created, err := models.ParseTime(issue.Fields.Created); err == nil {
+				return filepath.Join(d.getDataPath(), created.UTC().Format("2006/01/02"), name)
+			}
+		}
+		return filepath.Join(d.getDataPath(), "undated", name)
+	default:
+		return d.idPath(issue.ID)
+	}
+}
+
+// linkKey indexes key as resolving to the record of issue
+func (d *DiskCache) linkKey(key string, issue *models.IssueWithHistory) error {
+	switch d.layout {
+	case LayoutFlat, LayoutDated:
+		record := d.recordPath(issue)
+		link := d.keyPath(key)
+		if record == link {
+			// The record is its own index entry
+			return nil
+		}
+		target, err := filepath.Rel(filepath.Dir(link), record)
+		if err != nil {
+			return err
+		}
+		return symlinkAtomic(target, link)
+	default:
+		return d.writeKeyIndex(key, issue.ID)
+	}
+}
+
+// writeRecord atomically writes an issue record, creating its directory
+// for layouts that partition records
+func (d *DiskCache) writeRecord(path string, data []byte) error {
+	if d.layout == LayoutDated {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write issue file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// lockStripes is the number of mutexes issue names are hashed across
+const lockStripes = 64
+
+// stripedLock guards per-issue files with a fixed set of RWMutexes. Names
+// that hash to the same stripe share a lock, which only costs some
+// unnecessary serialization.
+type stripedLock struct {
+	stripes [lockStripes]sync.RWMutex
+}
+
+// index returns the stripe for a name
+func (l *stripedLock) index(name string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return int(h.Sum32() % lockStripes)
+}
+
+// rlock takes a read lock for name and returns the unlock function
+func (l *stripedLock) rlock(name string) func() {
+	m := &l.stripes[l.index(name)]
+	m.RLock()
+	return m.RUnlock
+}
+
+// lock takes write locks for all names, in stripe order to avoid deadlock,
+// and returns the unlock function
+func (l *stripedLock) lock(names ...string) func() {
+	var held [lockStripes]bool
+	for _, name := range names {
+		held[l.index(name)] = true
+	}
+
+	var locked []*sync.RWMutex
+	for i := range held {
+		if held[i] {
+			l.stripes[i].Lock()
+			locked = append(locked, &l.stripes[i])
+		}
+	}
+
+	return func() {
+		for i := len(locked) - 1; i >= 0; i-- {
+			locked[i].Unlock()
+		}
+	}
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory and
+// renames it over path, so readers never observe a partially written file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// symlinkAtomic points link at target by creating a temporary symlink and
+// renaming it into place, so the link never disappears for readers
+func symlinkAtomic(target, link string) error {
+	tmpLink := fmt.Sprintf("%s.tmp-%d", link, os.Getpid())
+	os.Remove(tmpLink)
+	if err := os.Symlink(target, tmpLink); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpLink, link); err != nil {
+		os.Remove(tmpLink)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jctanner/go-jira-scraper/pkg/history"
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// markdownTableRows are the metadata table rows, as label and CSV column
+var markdownTableRows = [][2]string{
+	{"Type", "issuetype"},
+	{"Status", "status"},
+	{"Priority", "priority"},
+	{"Assignee", "assignee"},
+	{"Creator", "creator"},
+	{"Created", "created"},
+	{"Updated", "updated"},
+	{"Resolved", "resolutiondate"},
+	{"Labels", "labels"},
+	{"Components", "components"},
+	{"Fix versions", "fixversions"},
+	{"Affects versions", "versions"},
+}
+
+// RenderIssueMarkdown renders a cached issue as a readable Markdown document:
+// a heading, a metadata table, the description, the comments, and the
+// changelog oldest first. Missing fields are omitted.
+func RenderIssueMarkdown(cached *models.CachedIssue) string {
+	if cached == nil || cached.JiraData == nil {
+		return ""
+	}
+	issue := cached.JiraData
+
+	var b strings.Builder
+	heading := issue.Key
+	if issue.Fields != nil && issue.Fields.Summary != "" {
+		heading += ": " + issue.Fields.Summary
+	}
+	fmt.Fprintf(&b, "# %s\n\n", heading)
+
+	b.WriteString("| Field | Value |\n|---|---|\n")
+	for _, row := range markdownTableRows {
+		value := csvColumns[row[1]](issue)
+		if value == "" {
+			continue
+		}
+		value = strings.ReplaceAll(value, ";", ", ")
+		fmt.Fprintf(&b, "| %s | %s |\n", row[0], escapeMarkdownCell(value))
+	}
+	if !cached.CacheMetadata.FetchedAt.IsZero() {
+		fmt.Fprintf(&b, "| Fetched | %s |\n", cached.CacheMetadata.FetchedAt.Format("2006-01-02 15:04:05 MST"))
+	}
+
+	if issue.Fields != nil {
+		if description := issue.Fields.Description.Markdown(); description != "" {
+			fmt.Fprintf(&b, "\n## Description\n\n%s\n", strings.TrimSpace(description))
+		}
+
+		if issue.Fields.Comment != nil && len(issue.Fields.Comment.Comments) > 0 {
+			b.WriteString("\n## Comments\n")
+			for _, comment := range issue.Fields.Comment.Comments {
+				fmt.Fprintf(&b, "\n### %s, %s\n\n%s\n",
+					orUnknown(userName(comment.Author)), comment.Created, strings.TrimSpace(comment.Body.Markdown()))
+			}
+		}
+	}
+
+	if issue.Changelog != nil && len(issue.Changelog.Histories) > 0 {
+		b.WriteString("\n## History\n\n")
+		for _, h := range history.Merge(issue.Changelog).Histories {
+			for _, item := range h.Items {
+				fmt.Fprintf(&b, "- %s %s changed **%s**: %s → %s\n",
+					h.Created, orUnknown(userName(h.Author)), item.Field,
+					historyValue(item.FromString), historyValue(item.ToString))
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// escapeMarkdownCell keeps a value from breaking out of its table cell
+func escapeMarkdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	return strings.ReplaceAll(value, "\n", " ")
+}
+
+// orUnknown substitutes a placeholder for an empty user name
+func orUnknown(name string) string {
+	if name == "" {
+		return "Unknown"
+	}
+	return name
+}
+
+// historyValue renders one side of a field transition
+func historyValue(value *string) string {
+	if value == nil || *value == "" {
+		return "_(none)_"
+	}
+	return "`" + strings.ReplaceAll(*value, "`", "'") + "`"
+}
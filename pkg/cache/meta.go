@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// MetaStore is implemented by caches that store instance-level metadata
+// (field definitions, statuses, ...) alongside issues
+type MetaStore interface {
+	WriteMeta(name string, v any) error
+	ReadMeta(name string, v any) error
+}
+
+// Ensure DiskCache satisfies the MetaStore interface
+var _ MetaStore = (*DiskCache)(nil)
+
+// metaNamePattern restricts metadata names to safe file names
+var metaNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// metaPath returns the file for a metadata document
+// Format: .data/jira/<hostname>/.meta/<name>.json
+func (d *DiskCache) metaPath(name string) (string, error) {
+	if !metaNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid metadata name %q", name)
+	}
+	return filepath.Join(d.getDataPath(), ".meta", name+".json"), nil
+}
+
+// WriteMeta stores a metadata document as JSON under .meta/<name>.json
+func (d *DiskCache) WriteMeta(name string, v any) error {
+	path, err := d.metaPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create metadata directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s metadata: %w", name, err)
+	}
+
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s metadata: %w", name, err)
+	}
+	return nil
+}
+
+// ReadMeta loads a metadata document written by WriteMeta into v
+func (d *DiskCache) ReadMeta(name string, v any) error {
+	path, err := d.metaPath(name)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%s metadata not found in cache", name)
+		}
+		return fmt.Errorf("failed to read %s metadata: %w", name, err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal %s metadata: %w", name, err)
+	}
+	return nil
+}
+
+// GetFieldMeta returns the cached field definitions
+func (d *DiskCache) GetFieldMeta() ([]models.FieldMeta, error) {
+	var fields []models.FieldMeta
+	if err := d.ReadMeta("fields", &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// GetStatuses returns the status definitions stored by SyncInstanceMeta
+func (d *DiskCache) GetStatuses() ([]models.Status, error) {
+	var statuses []models.Status
+	if err := d.ReadMeta("statuses", &statuses); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// GetIssueTypes returns the issue type definitions stored by SyncInstanceMeta
+func (d *DiskCache) GetIssueTypes() ([]models.IssueType, error) {
+	var types []models.IssueType
+	if err := d.ReadMeta("issuetypes", &types); err != nil {
+		return nil, err
+	}
+	return types, nil
+}
+
+// FieldNames returns a map from field ID (e.g. customfield_10010) to its
+// human-readable name, built from the cached field definitions
+func (d *DiskCache) FieldNames() (map[string]string, error) {
+	fields, err := d.GetFieldMeta()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(fields))
+	for _, field := range fields {
+		names[field.ID] = field.Name
+	}
+	return names, nil
+}
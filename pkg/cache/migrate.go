@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// migrations upgrade a cached record from the keyed schema version to the
+// next one, given the record decoded with the current models and its stored
+// JSON, for data the current models no longer read. Version 0 records
+// predate versioning; re-serializing them through the current models is all
+// that is needed to bring them to version 1.
+var migrations = map[int]func(cached *models.CachedIssue, stored []byte) error{
+	0: func(cached *models.CachedIssue, stored []byte) error { return nil },
+	1: moveFetchedExtras,
+}
+
+// moveFetchedExtras moves remote links and available transitions, stored
+// in jira_data before version 2, to the cache metadata
+func moveFetchedExtras(cached *models.CachedIssue, stored []byte) error {
+	var legacy struct {
+		JiraData struct {
+			RemoteLinks []models.RemoteLink          `json:"remotelinks"`
+			Transitions []models.AvailableTransition `json:"transitions"`
+		} `json:"jira_data"`
+	}
+	if err := json.Unmarshal(stored, &legacy); err != nil {
+		return fmt.Errorf("failed to read remote links and transitions: %w", err)
+	}
+	if len(cached.CacheMetadata.RemoteLinks) == 0 {
+		cached.CacheMetadata.RemoteLinks = legacy.JiraData.RemoteLinks
+	}
+	if len(cached.CacheMetadata.Transitions) == 0 {
+		cached.CacheMetadata.Transitions = legacy.JiraData.Transitions
+	}
+	return nil
+}
+
+// Migrate upgrades all cached records older than models.CurrentSchemaVersion
+// in place, preserving their fetch metadata except fetched_by, which names
+// the tool doing the rewrite. It returns the number of records rewritten.
+func (d *DiskCache) Migrate() (int, error) {
+	paths, err := d.recordPaths()
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	for _, path := range paths {
+		stored, err := readIssueBytes(path)
+		if err != nil {
+			return migrated, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		cached, err := d.unmarshalRecord(stored)
+		if err != nil {
+			return migrated, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if cached.JiraData == nil || cached.CacheMetadata.SchemaVersion >= models.CurrentSchemaVersion {
+			continue
+		}
+
+		for version := cached.CacheMetadata.SchemaVersion; version < models.CurrentSchemaVersion; version++ {
+			migrate, ok := migrations[version]
+			if !ok {
+				return migrated, fmt.Errorf("no migration from schema version %d", version)
+			}
+			if err := migrate(cached, stored); err != nil {
+				return migrated, fmt.Errorf("failed to migrate %s from version %d: %w", cached.JiraData.Key, version, err)
+			}
+		}
+
+		meta := cached.CacheMetadata
+		meta.FetchedBy = ""
+		if _, err := d.WriteIssueWithMetadata(cached.JiraData, meta); err != nil {
+			return migrated, fmt.Errorf("failed to rewrite %s: %w", cached.JiraData.Key, err)
+		}
+		migrated++
+	}
+
+	if migrated > 0 {
+		log.Printf("Migrated %d cached issues to schema version %d", migrated, models.CurrentSchemaVersion)
+	}
+	return migrated, nil
+}
+
+// recordPaths returns the record file of every indexed issue in the
+// configured layout, once per record however many keys resolve to it
+func (d *DiskCache) recordPaths() ([]string, error) {
+	keys, err := d.listKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(keys))
+	var paths []string
+	for _, key := range keys {
+		path, ok := d.issuePath(key)
+		if !ok {
+			continue
+		}
+		record, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			// Dangling index entry
+			continue
+		}
+		if !seen[record] {
+			seen[record] = true
+			paths = append(paths, record)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,270 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// downgradeRecord rewrites the stored record of key as written before
+// schema versioning
+func downgradeRecord(t *testing.T, d *DiskCache, key string) {
+	t.Helper()
+	path, _ := d.issuePath(key)
+	record, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatalf("EvalSymlinks: %v", err)
+	}
+	data, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	delete(fields["_cache_metadata"].(map[string]any), "schema_version")
+	if data, err = json.Marshal(fields); err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if err := os.WriteFile(record, data, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+// datedIssue returns a minimal issue created on 2024-03-05
+func datedIssue(id, key string) *models.IssueWithHistory {
+	issue := testIssue(id, key, "summary "+key)
+	issue.Fields.Created = "2024-03-05T10:00:00.000+0000"
+	return issue
+}
+
+func TestMigrateLayouts(t *testing.T) {
+	for _, layout := range []Layout{LayoutByID, LayoutFlat, LayoutDated} {
+		t.Run(string(layout), func(t *testing.T) {
+			d := newTestCache(t)
+			if err := d.SetLayout(layout); err != nil {
+				t.Fatalf("SetLayout: %v", err)
+			}
+			keys := []string{"PROJ-1", "PROJ-2"}
+			for i, key := range keys {
+				if _, err := d.WriteIssue(datedIssue(string(rune('1'+i)), key), 0); err != nil {
+					t.Fatalf("WriteIssue: %v", err)
+				}
+				downgradeRecord(t, d, key)
+			}
+
+			migrated, err := d.Migrate()
+			if err != nil {
+				t.Fatalf("Migrate: %v", err)
+			}
+			if migrated != len(keys) {
+				t.Errorf("migrated %d records, want %d", migrated, len(keys))
+			}
+			for _, key := range keys {
+				cached, err := d.GetIssue(key)
+				if err != nil {
+					t.Fatalf("GetIssue(%s): %v", key, err)
+				}
+				if cached.CacheMetadata.SchemaVersion != models.CurrentSchemaVersion {
+					t.Errorf("%s schema version = %d, want %d", key, cached.CacheMetadata.SchemaVersion, models.CurrentSchemaVersion)
+				}
+			}
+
+			if migrated, err := d.Migrate(); err != nil || migrated != 0 {
+				t.Errorf("second Migrate = %d, %v, want nothing to do", migrated, err)
+			}
+		})
+	}
+}
+
+func TestMigrateToNamespaceDatedLayout(t *testing.T) {
+	d := newTestCache(t)
+	if err := d.SetLayout(LayoutDated); err != nil {
+		t.Fatalf("SetLayout: %v", err)
+	}
+	if _, err := d.WriteIssue(datedIssue("1", "PROJ-1"), 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+	if _, err := d.WriteIssue(testIssue("2", "PROJ-2", "undated"), 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+	// An existing namespace named like a year must not be moved
+	other, err := NewWithHostNamespace(d.baseDir, "https://jira.example.com", "2023")
+	if err != nil {
+		t.Fatalf("NewWithHostNamespace: %v", err)
+	}
+	if err := other.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	dst, err := MigrateToNamespace(d.baseDir, "https://jira.example.com", "prod")
+	if err != nil {
+		t.Fatalf("MigrateToNamespace: %v", err)
+	}
+	if err := dst.SetLayout(LayoutDated); err != nil {
+		t.Fatalf("SetLayout: %v", err)
+	}
+	for _, key := range []string{"PROJ-1", "PROJ-2"} {
+		if _, err := dst.GetIssue(key); err != nil {
+			t.Errorf("GetIssue(%s) after migration: %v", key, err)
+		}
+	}
+
+	for _, name := range []string{"2024", "undated"} {
+		if _, err := os.Lstat(filepath.Join(d.getDataPath(), name)); !os.IsNotExist(err) {
+			t.Errorf("%s left behind in the host directory", name)
+		}
+	}
+	if _, err := os.Stat(other.getDataPath()); err != nil {
+		t.Errorf("namespace 2023 was moved: %v", err)
+	}
+}
+
+func TestNamespaceRejectsReservedNames(t *testing.T) {
+	for _, namespace := range []string{"by_id", "BY_KEY", ".meta", "shards", "dictionary.jsonl"} {
+		if _, err := NewWithHostNamespace(t.TempDir(), "https://jira.example.com", namespace); err == nil {
+			t.Errorf("NewWithHostNamespace accepted %q", namespace)
+		}
+		if _, err := MigrateToNamespace(t.TempDir(), "https://jira.example.com", namespace); err == nil {
+			t.Errorf("MigrateToNamespace accepted %q", namespace)
+		}
+	}
+	for _, namespace := range []string{"prod", "2023", "by_id_old"} {
+		if _, err := NewWithHostNamespace(t.TempDir(), "https://jira.example.com", namespace); err != nil {
+			t.Errorf("NewWithHostNamespace(%q): %v", namespace, err)
+		}
+	}
+}
+
+func TestMigrateToNamespaceRollsBack(t *testing.T) {
+	d := newTestCache(t)
+	if _, err := d.WriteIssue(testIssue("1", "PROJ-1", "summary"), 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+
+	failed := errors.New("device busy")
+	rename = func(from, to string) error {
+		if filepath.Base(from) == "by_key" {
+			return failed
+		}
+		return os.Rename(from, to)
+	}
+	defer func() { rename = os.Rename }()
+
+	if _, err := MigrateToNamespace(d.baseDir, "https://jira.example.com", "prod"); !errors.Is(err, failed) {
+		t.Fatalf("MigrateToNamespace = %v, want the rename failure", err)
+	}
+	if _, err := d.GetIssue("PROJ-1"); err != nil {
+		t.Errorf("GetIssue after a failed migration: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(d.getDataPath(), "prod")); !os.IsNotExist(err) {
+		t.Errorf("namespace directory left behind: %v", err)
+	}
+}
+
+func TestRewritesStampCurrentFetchedBy(t *testing.T) {
+	d := newTestCache(t)
+	d.SetFetchedBy("old-tool/1.0")
+	for _, key := range []string{"PROJ-1", "PROJ-2"} {
+		if _, err := d.WriteIssue(datedIssue(key[len(key)-1:], key), 0); err != nil {
+			t.Fatalf("WriteIssue: %v", err)
+		}
+	}
+	before, err := d.GetIssue("PROJ-1")
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	downgradeRecord(t, d, "PROJ-1")
+
+	d.SetFetchedBy("new-tool/2.0")
+	if _, err := d.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if err := d.Touch("PROJ-2"); err != nil {
+		t.Fatalf("Touch: %v", err)
+	}
+
+	migrated, err := d.GetIssue("PROJ-1")
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	if migrated.CacheMetadata.FetchedBy != "new-tool/2.0" || !migrated.CacheMetadata.FetchedAt.Equal(before.CacheMetadata.FetchedAt) {
+		t.Errorf("migrated metadata = %+v, want fetched_by new-tool/2.0 and the original fetched_at", migrated.CacheMetadata)
+	}
+	touched, err := d.GetIssue("PROJ-2")
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	if touched.CacheMetadata.FetchedBy != "new-tool/2.0" {
+		t.Errorf("touched fetched_by = %q, want new-tool/2.0", touched.CacheMetadata.FetchedBy)
+	}
+}
+
+func TestMigrateMovesFetchedExtras(t *testing.T) {
+	d := newTestCache(t)
+	if _, err := d.WriteIssue(datedIssue("1", "PROJ-1"), 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+
+	// Rewrite the record as version 1 stored remote links and transitions
+	path, _ := d.issuePath("PROJ-1")
+	record, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatalf("EvalSymlinks: %v", err)
+	}
+	data, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	fields["_cache_metadata"].(map[string]any)["schema_version"] = 1
+	jiraData := fields["jira_data"].(map[string]any)
+	jiraData["remotelinks"] = []any{map[string]any{"id": 10, "object": map[string]any{"url": "https://example.com/pr/1", "title": "PR 1"}}}
+	jiraData["transitions"] = []any{map[string]any{"id": "21", "name": "Close"}}
+	if data, err = json.Marshal(fields); err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if err := os.WriteFile(record, data, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if migrated, err := d.Migrate(); err != nil || migrated != 1 {
+		t.Fatalf("Migrate = %d, %v; want 1, nil", migrated, err)
+	}
+	cached, err := d.GetIssue("PROJ-1")
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	meta := cached.CacheMetadata
+	if meta.SchemaVersion != models.CurrentSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", meta.SchemaVersion, models.CurrentSchemaVersion)
+	}
+	if len(meta.RemoteLinks) != 1 || meta.RemoteLinks[0].Object.URL != "https://example.com/pr/1" {
+		t.Errorf("RemoteLinks = %+v, want the stored PR link", meta.RemoteLinks)
+	}
+	if len(meta.Transitions) != 1 || meta.Transitions[0].Name != "Close" {
+		t.Errorf("Transitions = %+v, want Close", meta.Transitions)
+	}
+
+	data, err = os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	fields = nil
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	for _, name := range []string{"remotelinks", "transitions"} {
+		if _, ok := fields["jira_data"].(map[string]any)[name]; ok {
+			t.Errorf("jira_data.%s still stored after migration", name)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// cacheEntries are the files and directories a cache keeps under its data
+// path, as moved by MigrateToNamespace. The dated layout's <YYYY>
+// directories are found by datedEntries.
+var cacheEntries = []string{"by_id", "by_key", "undated", "raw", "index", "shards", "attachments", ".meta", ".sync", ".search", ".discovery", "dictionary.jsonl", "dictionary.jsonl.lock"}
+
+// yearDir matches the name of a dated layout year directory
+var yearDir = regexp.MustCompile(`^\d{4}$`)
+
+// monthDir matches the name of a dated layout month directory
+var monthDir = regexp.MustCompile(`^\d{2}$`)
+
+// datedEntries returns the dated layout's year directories under a data
+// path. A directory is only taken for a year when it holds nothing but
+// month directories, so a namespace named like a year is left alone.
+func datedEntries(dataPath string) ([]string, error) {
+	entries, err := os.ReadDir(dataPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	var years []string
+	for _, entry := range entries {
+		if !entry.IsDir() || !yearDir.MatchString(entry.Name()) {
+			continue
+		}
+		months, err := os.ReadDir(filepath.Join(dataPath, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		isYear := len(months) > 0
+		for _, month := range months {
+			if !month.IsDir() || !monthDir.MatchString(month.Name()) {
+				isYear = false
+				break
+			}
+		}
+		if isYear {
+			years = append(years, entry.Name())
+		}
+	}
+	return years, nil
+}
+
+// rename moves cache entries; replaced in tests to simulate failures
+var rename = os.Rename
+
+// ValidateNamespace checks that a namespace does not name an entry of a
+// cache stored directly under the host directory (by_id, by_key, .meta,
+// ...), before or after escaping. Case is ignored, as on case-insensitive
+// filesystems.
+func ValidateNamespace(namespace string) error {
+	name := safeFilename(namespace)
+	for _, entry := range cacheEntries {
+		if strings.EqualFold(namespace, entry) || strings.EqualFold(name, entry) {
+			return fmt.Errorf("namespace %q is reserved for cache data", namespace)
+		}
+	}
+	return nil
+}
+
+// NewWithHostNamespace creates a DiskCache for a JIRA host whose data lives
+// in a namespace below the host directory (.data/jira/<host>/<namespace>/),
+// so environments sharing a hostname (e.g. staging and production tenants)
+// stay apart. An empty namespace is equivalent to NewWithHost. The
+// namespace is escaped to a single path component, and names reserved for
+// cache data are rejected (see ValidateNamespace).
+func NewWithHostNamespace(baseDir, jiraURL, namespace string) (*DiskCache, error) {
+	d := NewWithHost(baseDir, jiraURL)
+	if namespace != "" {
+		if err := ValidateNamespace(namespace); err != nil {
+			return nil, err
+		}
+		d.namespace = safeFilename(namespace)
+	}
+	return d, nil
+}
+
+// MigrateToNamespace moves the cache stored directly under a host's
+// directory into the given namespace, returning a cache opened on the
+// namespace. Entries are renamed, not copied, so the move is cheap; it
+// fails without moving anything if the namespace already holds data, and
+// moves entries back if a rename fails partway. Nothing is moved when the
+// host has no non-namespaced cache.
+func MigrateToNamespace(baseDir, jiraURL, namespace string) (*DiskCache, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+
+	src := NewWithHost(baseDir, jiraURL)
+	dst, err := NewWithHostNamespace(baseDir, jiraURL, namespace)
+	if err != nil {
+		return nil, err
+	}
+	srcPath, dstPath := src.getDataPath(), dst.getDataPath()
+
+	years, err := datedEntries(srcPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []string
+	for _, name := range append(years, cacheEntries...) {
+		if _, err := os.Lstat(filepath.Join(dstPath, name)); err == nil {
+			return nil, fmt.Errorf("namespace %s already contains %s", namespace, name)
+		}
+		if _, err := os.Lstat(filepath.Join(srcPath, name)); err == nil {
+			entries = append(entries, name)
+		}
+	}
+
+	if err := os.MkdirAll(dstPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create namespace directory: %w", err)
+	}
+	// by_key symlinks are relative (../by_id/... or ../<YYYY>/...), so they
+	// stay valid
+	for i, name := range entries {
+		if err := rename(filepath.Join(srcPath, name), filepath.Join(dstPath, name)); err != nil {
+			err = fmt.Errorf("failed to move %s: %w", name, err)
+			return nil, errors.Join(err, rollbackMigration(srcPath, dstPath, entries[:i]))
+		}
+	}
+
+	if err := dst.Initialize(); err != nil {
+		return nil, err
+	}
+	log.Printf("Migrated %d cache entries into namespace %s", len(entries), namespace)
+	return dst, nil
+}
+
+// rollbackMigration moves entries already migrated to a namespace back to
+// the host directory, in reverse order, and removes the namespace directory
+// if that leaves it empty
+func rollbackMigration(srcPath, dstPath string, moved []string) error {
+	var errs []error
+	for i := len(moved) - 1; i >= 0; i-- {
+		name := moved[i]
+		if err := rename(filepath.Join(dstPath, name), filepath.Join(srcPath, name)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to move %s back: %w", name, err))
+		}
+	}
+	if len(errs) == 0 {
+		os.Remove(dstPath)
+	}
+	return errors.Join(errs...)
+}
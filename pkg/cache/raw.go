@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// RawStore is implemented by caches that can keep raw API responses
+type RawStore interface {
+	WriteRaw(id string, data []byte) error
+	StoresRaw() bool
+}
+
+// Ensure DiskCache satisfies the RawStore interface
+var _ RawStore = (*DiskCache)(nil)
+
+// rawPath returns the raw response file for an issue ID, escaped with
+// safeFilename so an unexpected ID cannot leave the raw directory
+// Format: .data/jira/<hostname>/raw/<id>.json
+func (d *DiskCache) rawPath(id string) string {
+	return filepath.Join(d.getDataPath(), "raw", safeFilename(id)+".json")
+}
+
+// StoresRaw reports whether WriteRaw keeps responses: StoreRaw is enabled
+// and redaction is not configured
+func (d *DiskCache) StoresRaw() bool {
+	return d.storeRaw && len(d.redaction.Fields) == 0
+}
+
+// warnRawRedacted logs when raw storage is enabled but skipped because
+// redaction is configured; called by the setters of either option
+func (d *DiskCache) warnRawRedacted() {
+	if d.storeRaw && len(d.redaction.Fields) > 0 {
+		log.Printf("Warning: raw responses are not stored while redaction is configured")
+	}
+}
+
+// WriteRaw stores an issue's raw API response when StoreRaw is enabled and
+// is a no-op otherwise. Raw responses cannot be redacted, so they are not
+// stored while redaction is configured.
+//
+// The write holds the same per-ID lock as WriteIssue and goes through a
+// temp file and rename, so concurrent workers storing the same ID never
+// interleave and readers never see a partial file.
+func (d *DiskCache) WriteRaw(id string, data []byte) error {
+	if !d.StoresRaw() || id == "" || len(data) == 0 {
+		return nil
+	}
+
+	path := d.rawPath(id)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create raw directory: %w", err)
+	}
+
+	defer d.locks.lock("id:" + id)()
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write raw response: %w", err)
+	}
+	return nil
+}
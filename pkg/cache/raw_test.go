@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentWriteRawSameID(t *testing.T) {
+	d := newTestCache(t)
+	d.SetStoreRaw(true)
+
+	const writers = 16
+	payloads := make([][]byte, writers)
+	for i := range payloads {
+		// Large enough that an interleaved write would be visible
+		data, err := json.Marshal(map[string]string{
+			"id":  "10001",
+			"pad": string(bytes.Repeat([]byte{byte('a' + i)}, 256<<10)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		payloads[i] = data
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := d.WriteRaw("10001", payloads[i]); err != nil {
+				t.Errorf("WriteRaw: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(d.rawPath("10001"))
+	if err != nil {
+		t.Fatalf("reading raw file: %v", err)
+	}
+	if !json.Valid(data) {
+		t.Fatalf("raw file is not valid JSON (%d bytes)", len(data))
+	}
+	complete := false
+	for _, payload := range payloads {
+		if bytes.Equal(data, payload) {
+			complete = true
+		}
+	}
+	if !complete {
+		t.Errorf("raw file matches none of the written payloads")
+	}
+
+	// No temp files may be left behind
+	entries, err := os.ReadDir(filepath.Dir(d.rawPath("10001")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("raw directory has %d entries, want 1", len(entries))
+	}
+}
+
+func TestConcurrentWriteIssueSameID(t *testing.T) {
+	d := newTestCache(t)
+
+	const writers = 16
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			issue := testIssue("10001", "PROJ-1", fmt.Sprintf("summary %d", i))
+			if _, err := d.WriteIssue(issue, 0); err != nil {
+				t.Errorf("WriteIssue: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(d.idPath("10001"))
+	if err != nil {
+		t.Fatalf("reading record: %v", err)
+	}
+	cached, err := d.unmarshalRecord(data)
+	if err != nil {
+		t.Fatalf("record is not valid: %v", err)
+	}
+	if cached.JiraData == nil || cached.JiraData.ID != "10001" {
+		t.Errorf("record = %+v, want issue 10001", cached.JiraData)
+	}
+}
+
+func TestRawSkippedUnderRedactionWarns(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	d := newTestCache(t)
+	d.SetStoreRaw(true)
+	if !d.StoresRaw() || logs.Len() != 0 {
+		t.Fatalf("StoresRaw = %v, logs %q before redaction", d.StoresRaw(), logs.String())
+	}
+	if err := d.SetRedaction(RedactionConfig{Fields: map[string]RedactionMode{"*.emailAddress": RedactDrop}}); err != nil {
+		t.Fatalf("SetRedaction: %v", err)
+	}
+	if d.StoresRaw() {
+		t.Error("StoresRaw = true with redaction configured")
+	}
+	if !strings.Contains(logs.String(), "raw responses are not stored while redaction is configured") {
+		t.Errorf("no warning logged; got %q", logs.String())
+	}
+
+	if err := d.WriteRaw("10001", []byte(`{"id":"10001"}`)); err != nil {
+		t.Fatalf("WriteRaw: %v", err)
+	}
+	if _, err := os.Stat(d.rawPath("10001")); !os.IsNotExist(err) {
+		t.Errorf("raw response stored under redaction: %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// RedactionMode selects how a personal-data field is persisted
+type RedactionMode string
+
+const (
+	// RedactKeep stores the value unchanged
+	RedactKeep RedactionMode = "keep"
+	// RedactDrop blanks the value
+	RedactDrop RedactionMode = "drop"
+	// RedactHash replaces the value with a salted SHA-256 digest, so the same
+	// person maps to the same token across issues without being identifiable
+	RedactHash RedactionMode = "hash"
+)
+
+// RedactionConfig controls which user fields are redacted before an issue
+// is written to the cache.
+//
+// Fields maps "<role>.<attribute>" paths to a mode. Roles are assignee,
+// creator, reporter, comment (comment authors and editors), attachment
+// (attachment authors) and changelog (history authors); attributes are
+// name, key, displayName, emailAddress and accountId. A "*" role applies to
+// every role, with explicit roles taking precedence, e.g.
+// {"*.emailAddress": RedactDrop, "assignee.displayName": RedactHash}.
+//
+// Changelog items recording a change of assignee or reporter are redacted
+// with that role's rules: from/to hold a user identifier and take the
+// strictest of the name, key and accountId modes; fromString/toString hold
+// the display name.
+type RedactionConfig struct {
+	Fields map[string]RedactionMode
+	Salt   string // Salt for RedactHash; keep it stable to keep hashes stable
+}
+
+// SetRedaction configures redaction applied by WriteIssue. A zero config
+// disables redaction. Raw responses are not stored while it is enabled,
+// and a warning is logged if StoreRaw is set.
+func (d *DiskCache) SetRedaction(config RedactionConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	d.redaction = config
+	d.warnRawRedacted()
+	return nil
+}
+
+// Validate checks that every field path and mode is recognized
+func (r RedactionConfig) Validate() error {
+	for path, mode := range r.Fields {
+		if !strings.Contains(path, ".") {
+			return fmt.Errorf("invalid redaction field %q: expected <role>.<attribute>", path)
+		}
+		switch mode {
+		case RedactKeep, RedactDrop, RedactHash:
+		default:
+			return fmt.Errorf("invalid redaction mode %q for %s", mode, path)
+		}
+	}
+	return nil
+}
+
+// Redact returns a copy of issue with the configured fields redacted. The
+// original issue is left untouched.
+func (r RedactionConfig) Redact(issue *models.IssueWithHistory) (*models.IssueWithHistory, error) {
+	if len(r.Fields) == 0 || issue == nil {
+		return issue, nil
+	}
+
+	// Deep copy so callers can keep using the unredacted issue
+	data, err := json.Marshal(issue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy issue for redaction: %w", err)
+	}
+	var redacted models.IssueWithHistory
+	if err := json.Unmarshal(data, &redacted); err != nil {
+		return nil, fmt.Errorf("failed to copy issue for redaction: %w", err)
+	}
+
+	if fields := redacted.Fields; fields != nil {
+		r.redactUser("assignee", fields.Assignee)
+		r.redactUser("creator", fields.Creator)
+		r.redactUser("reporter", fields.Reporter)
+		for i := range fields.Attachments {
+			r.redactUser("attachment", fields.Attachments[i].Author)
+		}
+		if fields.Comment != nil {
+			for i := range fields.Comment.Comments {
+				r.redactUser("comment", fields.Comment.Comments[i].Author)
+				r.redactUser("comment", fields.Comment.Comments[i].UpdateAuthor)
+			}
+		}
+	}
+	if redacted.Changelog != nil {
+		for i := range redacted.Changelog.Histories {
+			history := &redacted.Changelog.Histories[i]
+			r.redactUser("changelog", history.Author)
+			for j := range history.Items {
+				r.redactUserChange(&history.Items[j])
+			}
+		}
+	}
+
+	return &redacted, nil
+}
+
+// redactUser applies the configured modes to a user in the given role
+func (r RedactionConfig) redactUser(role string, user *models.User) {
+	if user == nil {
+		return
+	}
+	user.Name = r.apply(role, "name", user.Name)
+	user.Key = r.apply(role, "key", user.Key)
+	user.DisplayName = r.apply(role, "displayName", user.DisplayName)
+	user.EmailAddress = r.apply(role, "emailAddress", user.EmailAddress)
+	user.AccountID = r.apply(role, "accountId", user.AccountID)
+}
+
+// userFields are the changelog fields whose values are users, as roles
+var userFields = map[string]string{
+	"assignee": "assignee",
+	"reporter": "reporter",
+}
+
+// redactUserChange applies a role's modes to a changelog item changing a
+// user field; other items are left alone
+func (r RedactionConfig) redactUserChange(item *models.HistoryItem) {
+	role, ok := userFields[strings.ToLower(item.Field)]
+	if !ok {
+		return
+	}
+
+	// The identifier may be a name, a key or an account ID depending on
+	// the deployment, so take the strictest of their modes
+	idMode := RedactKeep
+	for _, attribute := range []string{"name", "key", "accountId"} {
+		switch r.mode(role, attribute) {
+		case RedactDrop:
+			idMode = RedactDrop
+		case RedactHash:
+			if idMode != RedactDrop {
+				idMode = RedactHash
+			}
+		}
+	}
+	nameMode := r.mode(role, "displayName")
+
+	for _, value := range []*string{item.From, item.To} {
+		if value != nil {
+			*value = r.redact(idMode, *value)
+		}
+	}
+	for _, value := range []*string{item.FromString, item.ToString} {
+		if value != nil {
+			*value = r.redact(nameMode, *value)
+		}
+	}
+}
+
+// mode returns the configured mode for a role's attribute
+func (r RedactionConfig) mode(role, attribute string) RedactionMode {
+	if mode, ok := r.Fields[role+"."+attribute]; ok {
+		return mode
+	}
+	return r.Fields["*."+attribute]
+}
+
+// apply redacts a single value according to its role and attribute
+func (r RedactionConfig) apply(role, attribute, value string) string {
+	return r.redact(r.mode(role, attribute), value)
+}
+
+// redact applies a mode to a single value
+func (r RedactionConfig) redact(mode RedactionMode, value string) string {
+	if value == "" {
+		return value
+	}
+
+	switch mode {
+	case RedactDrop:
+		return ""
+	case RedactHash:
+		sum := sha256.Sum256([]byte(r.Salt + value))
+		return "hash:" + hex.EncodeToString(sum[:8])
+	default:
+		return value
+	}
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// personalIssue returns an issue naming alice as reporter, attachment
+// author and former assignee, and bob as assignee
+func personalIssue() *models.IssueWithHistory {
+	alice := func() *models.User {
+		return &models.User{Name: "alice", Key: "alice", DisplayName: "Alice Smith", EmailAddress: "alice@example.com"}
+	}
+	issue := testIssue("10001", "PROJ-1", "summary")
+	issue.Fields.Assignee = &models.User{Name: "bob", DisplayName: "Bob Jones"}
+	issue.Fields.Reporter = alice()
+	issue.Fields.Attachments = []models.Attachment{{ID: "1", Filename: "log.txt", Author: alice()}}
+	issue.Changelog = &models.Changelog{Histories: []models.History{{
+		ID:     "1",
+		Author: alice(),
+		Items: []models.HistoryItem{
+			{Field: "assignee", From: strPtr("alice"), FromString: strPtr("Alice Smith"), To: strPtr("bob"), ToString: strPtr("Bob Jones")},
+			{Field: "Reporter", To: strPtr("alice"), ToString: strPtr("Alice Smith")},
+			{Field: "status", FromString: strPtr("Open"), ToString: strPtr("Done")},
+		},
+	}}}
+	return issue
+}
+
+func TestRedactCoversAllUserFields(t *testing.T) {
+	config := RedactionConfig{Fields: map[string]RedactionMode{
+		"*.name":         RedactDrop,
+		"*.key":          RedactDrop,
+		"*.displayName":  RedactDrop,
+		"*.accountId":    RedactDrop,
+		"*.emailAddress": RedactDrop,
+	}}
+	issue := personalIssue()
+
+	redacted, err := config.Redact(issue)
+	if err != nil {
+		t.Fatalf("Redact: %v", err)
+	}
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	for _, personal := range []string{"alice", "Alice", "bob", "Bob"} {
+		if strings.Contains(string(data), personal) {
+			t.Errorf("redacted issue still contains %q:\n%s", personal, data)
+		}
+	}
+
+	// Other changelog items and the original issue are untouched
+	if status := redacted.Changelog.Histories[0].Items[2]; *status.FromString != "Open" || *status.ToString != "Done" {
+		t.Errorf("status item = %+v, want it unchanged", status)
+	}
+	if issue.Fields.Reporter.Name != "alice" || *issue.Changelog.Histories[0].Items[0].From != "alice" {
+		t.Error("Redact modified the original issue")
+	}
+}
+
+func TestRedactHashesChangelogUsersConsistently(t *testing.T) {
+	config := RedactionConfig{
+		Fields: map[string]RedactionMode{"*.name": RedactHash, "*.displayName": RedactHash},
+		Salt:   "salt",
+	}
+
+	redacted, err := config.Redact(personalIssue())
+	if err != nil {
+		t.Fatalf("Redact: %v", err)
+	}
+	item := redacted.Changelog.Histories[0].Items[0]
+	if *item.To != redacted.Fields.Assignee.Name || *item.ToString != redacted.Fields.Assignee.DisplayName {
+		t.Errorf("assignee change to %q (%q), want the assignee's hashes %q (%q)",
+			*item.To, *item.ToString, redacted.Fields.Assignee.Name, redacted.Fields.Assignee.DisplayName)
+	}
+	if *item.From != redacted.Fields.Reporter.Name {
+		t.Errorf("assignee change from %q, want alice's hash %q", *item.From, redacted.Fields.Reporter.Name)
+	}
+}
@@ -0,0 +1,219 @@
+// Package s3 provides an object-storage implementation of cache.Cache.
+//
+// Records are stored in the same CachedIssue JSON format as DiskCache, under
+// keys of the form <prefix>/jira/<host>/by_id/<id>.json, so caches can be
+// copied between disk and object storage without conversion. A small pointer
+// object at <prefix>/jira/<host>/by_key/<key>.json maps each issue key to its
+// ID.
+package s3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jctanner/go-jira-scraper/pkg/cache"
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// ErrNotFound is returned by an ObjectStore when an object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// ObjectStore is the minimal object-storage API the cache needs. HTTPStore
+// implements it for S3-compatible services; other SDK clients can be adapted.
+type ObjectStore interface {
+	GetObject(ctx context.Context, key string) ([]byte, error)
+	PutObject(ctx context.Context, key string, data []byte) error
+	ListObjects(ctx context.Context, prefix string) ([]string, error)
+}
+
+// keyPointer is the body of a by_key index object
+type keyPointer struct {
+	ID string `json:"id"`
+}
+
+// Cache stores cached issues in an object store
+type Cache struct {
+	store    ObjectStore
+	prefix   string
+	jiraHost string
+	timeout  time.Duration
+}
+
+// Ensure Cache satisfies the cache.Cache interface
+var _ cache.Cache = (*Cache)(nil)
+
+// New creates an object-storage cache for the given JIRA instance. prefix is
+// prepended to every object key and may be empty.
+func New(store ObjectStore, prefix string, jiraURL string) *Cache {
+	host := "unknown"
+	if parsed, err := url.Parse(jiraURL); err == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
+	return &Cache{
+		store:    store,
+		prefix:   strings.Trim(prefix, "/"),
+		jiraHost: host,
+		timeout:  60 * time.Second,
+	}
+}
+
+// objectKey builds the full object key for a path below the host namespace
+func (c *Cache) objectKey(parts ...string) string {
+	elems := []string{"jira", c.jiraHost}
+	if c.prefix != "" {
+		elems = append([]string{c.prefix}, elems...)
+	}
+	return path.Join(append(elems, parts...)...)
+}
+
+// context returns a context bounded by the per-operation timeout
+func (c *Cache) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.timeout)
+}
+
+// WriteIssue stores an issue with fetch metadata
+func (c *Cache) WriteIssue(issue *models.IssueWithHistory, duration time.Duration) (string, error) {
+	return c.WriteIssueWithMetadata(issue, models.CacheMetadata{
+		APICallDurationMS: duration.Milliseconds(),
+	})
+}
+
+// WriteIssueWithMetadata stores an issue with caller-supplied metadata.
+// FetchedAt and FetchedBy are filled in when left empty.
+func (c *Cache) WriteIssueWithMetadata(issue *models.IssueWithHistory, meta models.CacheMetadata) (string, error) {
+	if meta.FetchedAt.IsZero() {
+		meta.FetchedAt = time.Now().UTC()
+	}
+	if meta.FetchedBy == "" {
+		meta.FetchedBy = cache.DefaultFetchedBy()
+	}
+	meta.SchemaVersion = models.CurrentSchemaVersion
+
+	cached := &models.CachedIssue{
+		CacheMetadata: meta,
+		JiraData:      issue,
+	}
+
+	data, err := json.MarshalIndent(cached, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal issue: %w", err)
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	idKey := c.objectKey("by_id", issue.ID+".json")
+	if err := c.store.PutObject(ctx, idKey, data); err != nil {
+		return "", fmt.Errorf("failed to write issue object: %w", err)
+	}
+
+	pointer, err := json.Marshal(keyPointer{ID: issue.ID})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal key index: %w", err)
+	}
+	if err := c.store.PutObject(ctx, c.objectKey("by_key", issue.Key+".json"), pointer); err != nil {
+		return "", fmt.Errorf("failed to write key index: %w", err)
+	}
+
+	return idKey, nil
+}
+
+// resolveID looks up the issue ID for a key via the by_key index
+func (c *Cache) resolveID(ctx context.Context, key string) (string, error) {
+	data, err := c.store.GetObject(ctx, c.objectKey("by_key", key+".json"))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return "", fmt.Errorf("issue not found in cache")
+		}
+		return "", fmt.Errorf("failed to read key index: %w", err)
+	}
+
+	var pointer keyPointer
+	if err := json.Unmarshal(data, &pointer); err != nil {
+		return "", fmt.Errorf("failed to unmarshal key index: %w", err)
+	}
+	return pointer.ID, nil
+}
+
+// GetIssue retrieves an issue by key
+func (c *Cache) GetIssue(key string) (*models.CachedIssue, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	id, err := c.resolveID(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return c.getIssueByID(ctx, id)
+}
+
+// GetIssueByID retrieves an issue by ID
+func (c *Cache) GetIssueByID(id string) (*models.CachedIssue, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	return c.getIssueByID(ctx, id)
+}
+
+// getIssueByID reads and unmarshals an issue object
+func (c *Cache) getIssueByID(ctx context.Context, id string) (*models.CachedIssue, error) {
+	data, err := c.store.GetObject(ctx, c.objectKey("by_id", id+".json"))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("issue not found in cache")
+		}
+		return nil, fmt.Errorf("failed to read issue object: %w", err)
+	}
+
+	var cached models.CachedIssue
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal issue: %w", err)
+	}
+	return &cached, nil
+}
+
+// Exists checks if an issue exists in the cache
+func (c *Cache) Exists(key string) bool {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	_, err := c.resolveID(ctx, key)
+	return err == nil
+}
+
+// ListIssues returns all cached issue keys
+func (c *Cache) ListIssues() ([]string, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	prefix := c.objectKey("by_key") + "/"
+	objects, err := c.store.ListObjects(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cache objects: %w", err)
+	}
+
+	keys := []string{}
+	for _, object := range objects {
+		name := strings.TrimPrefix(object, prefix)
+		if strings.Contains(name, "/") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		keys = append(keys, strings.TrimSuffix(name, ".json"))
+	}
+	return keys, nil
+}
+
+// GetLastFetched returns when an issue was last fetched
+func (c *Cache) GetLastFetched(key string) (time.Time, error) {
+	cached, err := c.GetIssue(key)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cached.CacheMetadata.FetchedAt, nil
+}
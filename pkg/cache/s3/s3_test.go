@@ -0,0 +1,107 @@
+package s3
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// memoryStore is an in-memory ObjectStore
+type memoryStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (m *memoryStore) GetObject(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return data, nil
+}
+
+func (m *memoryStore) PutObject(ctx context.Context, key string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = data
+	return nil
+}
+
+func (m *memoryStore) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var keys []string
+	for key := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func TestCacheReadWriteList(t *testing.T) {
+	store := &memoryStore{objects: map[string][]byte{}}
+	c := New(store, "backups/", "https://jira.example.com")
+
+	issue := &models.IssueWithHistory{}
+	issue.ID, issue.Key = "10001", "PROJ-1"
+	issue.Fields = &models.IssueFields{Summary: "first"}
+
+	path, err := c.WriteIssue(issue, 250*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+	if path != "backups/jira/jira.example.com/by_id/10001.json" {
+		t.Errorf("path = %q", path)
+	}
+	if _, ok := store.objects["backups/jira/jira.example.com/by_key/PROJ-1.json"]; !ok {
+		t.Error("no by_key pointer written")
+	}
+
+	cached, err := c.GetIssue("PROJ-1")
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	if cached.JiraData.Fields.Summary != "first" || cached.CacheMetadata.APICallDurationMS != 250 {
+		t.Errorf("GetIssue = %+v", cached)
+	}
+	if cached.CacheMetadata.FetchedAt.IsZero() || cached.CacheMetadata.FetchedBy == "" {
+		t.Errorf("metadata not filled in: %+v", cached.CacheMetadata)
+	}
+	if byID, err := c.GetIssueByID("10001"); err != nil || byID.JiraData.Key != "PROJ-1" {
+		t.Errorf("GetIssueByID = %+v, %v", byID, err)
+	}
+	if last, err := c.GetLastFetched("PROJ-1"); err != nil || !last.Equal(cached.CacheMetadata.FetchedAt) {
+		t.Errorf("GetLastFetched = %v, %v", last, err)
+	}
+
+	if !c.Exists("PROJ-1") || c.Exists("PROJ-2") {
+		t.Error("Exists reports the wrong issues")
+	}
+	if _, err := c.GetIssue("PROJ-2"); err == nil {
+		t.Error("GetIssue of a missing issue succeeded")
+	}
+
+	// Objects outside by_key, or nested below it, are not issues
+	store.objects["backups/jira/jira.example.com/by_key/nested/X.json"] = []byte(`{}`)
+	store.objects["backups/jira/jira.example.com/by_key/notes.txt"] = []byte(``)
+	issue.ID, issue.Key = "10002", "PROJ-2"
+	if _, err := c.WriteIssue(issue, 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+	keys, err := c.ListIssues()
+	if err != nil {
+		t.Fatalf("ListIssues: %v", err)
+	}
+	if strings.Join(keys, ",") != "PROJ-1,PROJ-2" {
+		t.Errorf("ListIssues = %v, want [PROJ-1 PROJ-2]", keys)
+	}
+}
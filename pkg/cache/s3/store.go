@@ -0,0 +1,287 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Config holds connection settings for an S3-compatible object store
+type Config struct {
+	Bucket   string
+	Region   string // Defaults to AWS_REGION, then us-east-1
+	Endpoint string // Defaults to https://s3.<region>.amazonaws.com; set for MinIO etc.
+
+	// Credentials default to AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+	// AWS_SESSION_TOKEN from the environment
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// HTTPStore is an ObjectStore for S3-compatible services using path-style
+// requests signed with AWS Signature Version 4
+type HTTPStore struct {
+	config     Config
+	httpClient *http.Client
+}
+
+// NewHTTPStore creates an HTTPStore, filling unset settings from the environment
+func NewHTTPStore(config Config) (*HTTPStore, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket is required")
+	}
+	if config.Region == "" {
+		config.Region = os.Getenv("AWS_REGION")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", config.Region)
+	}
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+	if config.AccessKeyID == "" && config.SecretAccessKey == "" {
+		config.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		config.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		config.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("s3 credentials are required")
+	}
+
+	return &HTTPStore{
+		config:     config,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// GetObject downloads an object, returning ErrNotFound if it does not exist
+func (s *HTTPStore) GetObject(ctx context.Context, key string) ([]byte, error) {
+	body, status, err := s.do(ctx, "GET", key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("s3 GET %s returned status %d: %s", key, status, string(body))
+	}
+	return body, nil
+}
+
+// PutObject uploads an object
+func (s *HTTPStore) PutObject(ctx context.Context, key string, data []byte) error {
+	body, status, err := s.do(ctx, "PUT", key, nil, data)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("s3 PUT %s returned status %d: %s", key, status, string(body))
+	}
+	return nil
+}
+
+// listBucketResult is the ListObjectsV2 response body
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// ListObjects returns the keys of all objects under prefix
+func (s *HTTPStore) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	token := ""
+
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		body, status, err := s.do(ctx, "GET", "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		if status != http.StatusOK {
+			return nil, fmt.Errorf("s3 list %s returned status %d: %s", prefix, status, string(body))
+		}
+
+		var result listBucketResult
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse list response: %w", err)
+		}
+		for _, content := range result.Contents {
+			keys = append(keys, content.Key)
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+
+	return keys, nil
+}
+
+// do sends a signed request and returns the response body and status
+func (s *HTTPStore) do(ctx context.Context, method, key string, query url.Values, payload []byte) ([]byte, int, error) {
+	canonicalURI := "/" + escapePath(s.config.Bucket)
+	if key != "" {
+		canonicalURI += "/" + escapePath(key)
+	}
+	canonicalQuery := canonicalQueryString(query)
+
+	reqURL := s.config.Endpoint + canonicalURI
+	if canonicalQuery != "" {
+		reqURL += "?" + canonicalQuery
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	s.sign(req, canonicalURI, canonicalQuery, payload, time.Now().UTC())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("s3 request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return body, resp.StatusCode, nil
+}
+
+// sign adds AWS Signature Version 4 headers to req
+func (s *HTTPStore) sign(req *http.Request, canonicalURI, canonicalQuery string, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.config.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" {
+			headers[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		canonicalQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), date)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// escapePath URI-encodes each segment of an object key per SigV4 rules
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// escape URI-encodes everything except RFC 3986 unreserved characters
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// canonicalQueryString encodes query parameters sorted by name
+func canonicalQueryString(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var parts []string
+	for _, name := range names {
+		for _, value := range query[name] {
+			parts = append(parts, escape(name)+"="+escape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// SchemaReport summarizes a ValidateSchema run
+type SchemaReport struct {
+	Total   int
+	OK      int
+	Invalid map[string]string // Record path relative to the data path -> reason
+}
+
+// ValidateSchema checks that every cached record has the required
+// structure: a _cache_metadata object with a valid fetched_at, and a
+// jira_data object with non-empty id and key strings, the key matching
+// the one the record is indexed under. Unlike decoding into the models, which
+// silently zero-fills missing fields, this catches partially populated or
+// hand-edited records. Each record is checked once, however many keys
+// point to it.
+func (d *DiskCache) ValidateSchema() (*SchemaReport, error) {
+	keys, err := d.listKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SchemaReport{Invalid: map[string]string{}}
+	checked := map[string]bool{}
+	for _, key := range keys {
+		path, ok := d.issuePath(key)
+		if !ok {
+			continue
+		}
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			resolved = path
+		}
+		if checked[resolved] {
+			continue
+		}
+		checked[resolved] = true
+
+		name, err := filepath.Rel(d.getDataPath(), resolved)
+		if err != nil {
+			name = resolved
+		}
+
+		report.Total++
+		if err := d.checkRecordSchema(resolved, key); err != nil {
+			report.Invalid[name] = err.Error()
+			continue
+		}
+		report.OK++
+	}
+
+	return report, nil
+}
+
+// checkRecordSchema validates the structure of one record file. key is a
+// key the record is indexed under; it must be the record's key unless the
+// issue has been moved, in which case old keys remain as aliases.
+func (d *DiskCache) checkRecordSchema(path, key string) error {
+	data, err := readIssueBytes(path)
+	if err != nil {
+		return err
+	}
+
+	var record map[string]json.RawMessage
+	if err := json.Unmarshal(data, &record); err != nil {
+		return fmt.Errorf("not a JSON object: %w", err)
+	}
+
+	var meta map[string]json.RawMessage
+	if err := requireObject(record, "_cache_metadata", &meta); err != nil {
+		return err
+	}
+	var fetchedAt time.Time
+	if raw, ok := meta["fetched_at"]; !ok {
+		return fmt.Errorf("_cache_metadata.fetched_at is missing")
+	} else if err := json.Unmarshal(raw, &fetchedAt); err != nil {
+		return fmt.Errorf("_cache_metadata.fetched_at is invalid: %w", err)
+	}
+	var renamed struct {
+		FromKey string `json:"from_key"`
+	}
+	if raw, ok := meta["renamed"]; ok {
+		if err := json.Unmarshal(raw, &renamed); err != nil {
+			return fmt.Errorf("_cache_metadata.renamed is invalid: %w", err)
+		}
+	}
+
+	var issue map[string]json.RawMessage
+	if err := requireObject(record, "jira_data", &issue); err != nil {
+		return err
+	}
+	if _, err := requireString(issue, "jira_data.id", "id"); err != nil {
+		return err
+	}
+	recordKey, err := requireString(issue, "jira_data.key", "key")
+	if err != nil {
+		return err
+	}
+	if recordKey != key && renamed.FromKey == "" {
+		return fmt.Errorf("jira_data.key %q does not match indexed key %q", recordKey, key)
+	}
+	return nil
+}
+
+// requireObject decodes the named member of object, which must be a JSON
+// object, into v
+func requireObject(object map[string]json.RawMessage, name string, v *map[string]json.RawMessage) error {
+	raw, ok := object[name]
+	if !ok {
+		return fmt.Errorf("%s is missing", name)
+	}
+	if err := json.Unmarshal(raw, v); err != nil || *v == nil {
+		return fmt.Errorf("%s is not an object", name)
+	}
+	return nil
+}
+
+// requireString returns the named member of object, which must be a
+// non-empty JSON string; path names it in errors
+func requireString(object map[string]json.RawMessage, path, name string) (string, error) {
+	raw, ok := object[name]
+	if !ok {
+		return "", fmt.Errorf("%s is missing", path)
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("%s is not a string", path)
+	}
+	if value == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return value, nil
+}
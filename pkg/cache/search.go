@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SearchCache is implemented by caches that can store discovery results
+type SearchCache interface {
+	GetSearchResult(jql string, limit int, maxAge time.Duration) ([]string, bool)
+	WriteSearchResult(jql string, limit int, keys []string) error
+}
+
+// Ensure DiskCache satisfies the SearchCache interface
+var _ SearchCache = (*DiskCache)(nil)
+
+// searchResult is the on-disk record of a discovery search
+type searchResult struct {
+	JQL      string    `json:"jql"`
+	Limit    int       `json:"limit"`
+	CachedAt time.Time `json:"cached_at"`
+	Keys     []string  `json:"keys"`
+}
+
+// searchResultPath returns the file used to cache results for a JQL query
+// Format: .data/jira/<hostname>/.search/<sha256 of jql>.json
+func (d *DiskCache) searchResultPath(jql string) string {
+	sum := sha256.Sum256([]byte(jql))
+	return filepath.Join(d.getDataPath(), ".search", hex.EncodeToString(sum[:])+".json")
+}
+
+// GetSearchResult returns the cached issue keys for a JQL query if they were
+// stored with the same limit less than maxAge ago
+func (d *DiskCache) GetSearchResult(jql string, limit int, maxAge time.Duration) ([]string, bool) {
+	data, err := os.ReadFile(d.searchResultPath(jql))
+	if err != nil {
+		return nil, false
+	}
+
+	var result searchResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, false
+	}
+
+	if result.JQL != jql || result.Limit != limit || time.Since(result.CachedAt) > maxAge {
+		return nil, false
+	}
+	return result.Keys, true
+}
+
+// WriteSearchResult stores the issue keys discovered for a JQL query
+func (d *DiskCache) WriteSearchResult(jql string, limit int, keys []string) error {
+	path := d.searchResultPath(jql)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create search cache directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(searchResult{
+		JQL:      jql,
+		Limit:    limit,
+		CachedAt: time.Now().UTC(),
+		Keys:     keys,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal search result: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write search result: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,383 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// shardSize is the number of consecutive numeric issue IDs grouped per shard
+const shardSize = 1000
+
+// shardEntry locates the latest record for an issue within a shard file
+type shardEntry struct {
+	ID        string    `json:"id"`
+	Shard     string    `json:"shard"`
+	Offset    int64     `json:"offset"`
+	Length    int64     `json:"length"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// shardIndex is the persisted key index plus the shard sizes it covers
+type shardIndex struct {
+	Entries    map[string]shardEntry `json:"entries"`
+	ShardSizes map[string]int64      `json:"shard_sizes"`
+}
+
+// ShardedCache stores issues in a small number of append-only shard files
+// instead of one file per issue, avoiding inode exhaustion and slow
+// directory scans on very large scrapes.
+//
+// Each shard (shards/<n>.jsonl) holds one compact CachedIssue JSON record per
+// line; rewriting an issue appends a new record and the index points at the
+// newest one. The index (shards/index.json) is an optimization saved by
+// Close: on Initialize, any shard data written after the last save is
+// replayed, so a crash never loses records. ShardedCache is safe for
+// concurrent use within one process.
+//
+// Writes are redacted and stamped like DiskCache writes (see SetRedaction
+// and SetFetchedBy). Records are always compact, one per line, and raw API
+// responses are not stored.
+type ShardedCache struct {
+	dir string
+
+	redaction RedactionConfig
+	fetchedBy string
+
+	mu    sync.RWMutex
+	index shardIndex
+}
+
+// Ensure ShardedCache satisfies the Cache interface
+var _ Cache = (*ShardedCache)(nil)
+
+// NewSharded creates a sharded cache under the same .data/jira/<hostname>
+// namespace as NewWithHost
+func NewSharded(baseDir string, jiraURL string) *ShardedCache {
+	d := NewWithHost(baseDir, jiraURL)
+	return &ShardedCache{
+		dir: filepath.Join(d.getDataPath(), "shards"),
+		index: shardIndex{
+			Entries:    map[string]shardEntry{},
+			ShardSizes: map[string]int64{},
+		},
+	}
+}
+
+// SetRedaction configures redaction applied to written issues, as
+// DiskCache.SetRedaction does. A zero config disables redaction.
+func (s *ShardedCache) SetRedaction(config RedactionConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	s.redaction = config
+	return nil
+}
+
+// SetFetchedBy sets the tool name and version recorded in the metadata of
+// issues written without one
+func (s *ShardedCache) SetFetchedBy(fetchedBy string) {
+	s.fetchedBy = fetchedBy
+}
+
+// Initialize creates the shard directory and loads the index, replaying any
+// shard data not yet covered by it
+func (s *ShardedCache) Initialize() error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", s.dir, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if data, err := os.ReadFile(s.indexPath()); err == nil {
+		var index shardIndex
+		if err := json.Unmarshal(data, &index); err == nil && index.Entries != nil && index.ShardSizes != nil {
+			s.index = index
+		} else {
+			log.Printf("Warning: shard index unreadable, rebuilding from shards")
+		}
+	}
+
+	shards, err := filepath.Glob(filepath.Join(s.dir, "*.jsonl"))
+	if err != nil {
+		return fmt.Errorf("failed to list shards: %w", err)
+	}
+	for _, shardPath := range shards {
+		if err := s.replayShard(filepath.Base(shardPath)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexPath returns the location of the persisted index
+func (s *ShardedCache) indexPath() string {
+	return filepath.Join(s.dir, "index.json")
+}
+
+// replayShard indexes records appended to a shard since the index was saved
+func (s *ShardedCache) replayShard(shard string) error {
+	file, err := os.Open(filepath.Join(s.dir, shard))
+	if err != nil {
+		return fmt.Errorf("failed to open shard %s: %w", shard, err)
+	}
+	defer file.Close()
+
+	offset := s.index.ShardSizes[shard]
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek shard %s: %w", shard, err)
+	}
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			var cached models.CachedIssue
+			if jsonErr := json.Unmarshal(line, &cached); jsonErr == nil && cached.JiraData != nil {
+				s.index.Entries[cached.JiraData.Key] = shardEntry{
+					ID:        cached.JiraData.ID,
+					Shard:     shard,
+					Offset:    offset,
+					Length:    int64(len(line)),
+					FetchedAt: cached.CacheMetadata.FetchedAt,
+				}
+			}
+			offset += int64(len(line))
+		}
+		if err == io.EOF {
+			// A trailing partial line is an interrupted write; it is
+			// overwritten by the next append
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read shard %s: %w", shard, err)
+		}
+	}
+
+	s.index.ShardSizes[shard] = offset
+	return nil
+}
+
+// shardFor returns the shard file name for an issue ID
+func shardFor(id string) string {
+	if n, err := strconv.ParseInt(id, 10, 64); err == nil && n >= 0 {
+		return fmt.Sprintf("%06d.jsonl", n/shardSize)
+	}
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return fmt.Sprintf("x%04d.jsonl", h.Sum32()%1000)
+}
+
+// WriteIssue stores an issue with fetch metadata
+func (s *ShardedCache) WriteIssue(issue *models.IssueWithHistory, duration time.Duration) (string, error) {
+	return s.WriteIssueWithMetadata(issue, models.CacheMetadata{
+		APICallDurationMS: duration.Milliseconds(),
+	})
+}
+
+// WriteIssueWithMetadata appends an issue record to its shard
+func (s *ShardedCache) WriteIssueWithMetadata(issue *models.IssueWithHistory, meta models.CacheMetadata) (string, error) {
+	stampMetadata(&meta, s.fetchedBy)
+
+	// Strip personal data before anything touches disk
+	issue, err := s.redaction.Redact(issue)
+	if err != nil {
+		return "", err
+	}
+	return s.appendRecord(issue, meta)
+}
+
+// appendRecord appends a record that has already been redacted and stamped
+func (s *ShardedCache) appendRecord(issue *models.IssueWithHistory, meta models.CacheMetadata) (string, error) {
+	data, err := json.Marshal(&models.CachedIssue{
+		CacheMetadata: meta,
+		JiraData:      issue,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal issue: %w", err)
+	}
+	data = append(data, '\n')
+
+	shard := shardFor(issue.ID)
+	shardPath := filepath.Join(s.dir, shard)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(shardPath, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to open shard: %w", err)
+	}
+	defer file.Close()
+
+	// Append at the indexed end, overwriting any partial record left by an
+	// interrupted write
+	offset := s.index.ShardSizes[shard]
+	if _, err := file.WriteAt(data, offset); err != nil {
+		return "", fmt.Errorf("failed to write shard: %w", err)
+	}
+	if err := file.Truncate(offset + int64(len(data))); err != nil {
+		return "", fmt.Errorf("failed to truncate shard: %w", err)
+	}
+
+	s.index.Entries[issue.Key] = shardEntry{
+		ID:        issue.ID,
+		Shard:     shard,
+		Offset:    offset,
+		Length:    int64(len(data)),
+		FetchedAt: meta.FetchedAt,
+	}
+	s.index.ShardSizes[shard] = offset + int64(len(data))
+
+	return shardPath, nil
+}
+
+// GetIssue retrieves an issue by key
+func (s *ShardedCache) GetIssue(key string) (*models.CachedIssue, error) {
+	s.mu.RLock()
+	entry, ok := s.index.Entries[key]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("issue not found in cache")
+	}
+
+	file, err := os.Open(filepath.Join(s.dir, entry.Shard))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open shard: %w", err)
+	}
+	defer file.Close()
+
+	data := make([]byte, entry.Length)
+	if _, err := file.ReadAt(data, entry.Offset); err != nil {
+		return nil, fmt.Errorf("failed to read issue record: %w", err)
+	}
+
+	var cached models.CachedIssue
+	if err := json.Unmarshal(bytes.TrimSpace(data), &cached); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal issue: %w", err)
+	}
+	return &cached, nil
+}
+
+// Exists checks if an issue exists in the cache
+func (s *ShardedCache) Exists(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.index.Entries[key]
+	return ok
+}
+
+// ListIssues returns all cached issue keys, sorted
+func (s *ShardedCache) ListIssues() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.index.Entries))
+	for key := range s.index.Entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// GetLastFetched returns when an issue was last fetched
+func (s *ShardedCache) GetLastFetched(key string) (time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.index.Entries[key]
+	if !ok {
+		return time.Time{}, fmt.Errorf("issue not found in cache")
+	}
+	return entry.FetchedAt, nil
+}
+
+// Close persists the index so the next Initialize does not need to replay
+// the shards
+func (s *ShardedCache) Close() error {
+	s.mu.RLock()
+	data, err := json.Marshal(s.index)
+	s.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal shard index: %w", err)
+	}
+
+	if err := writeFileAtomic(s.indexPath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write shard index: %w", err)
+	}
+	return nil
+}
+
+// Compact migrates every issue from the per-file cache into a sharded cache
+// in the same namespace, preserving each record's metadata. The per-file
+// records are left in place so the migration can be verified before they
+// are removed. The sharded cache inherits the redaction and FetchedBy
+// settings; records are copied as stored, without being redacted again.
+// Issues already compacted with the same fetch time are skipped, so Compact
+// can be re-run after an interruption or a later scrape. It fails when raw
+// storage is enabled, which the sharded cache does not support. It returns
+// the sharded cache and the number of issues copied.
+func (d *DiskCache) Compact() (*ShardedCache, int, error) {
+	if d.storeRaw {
+		return nil, 0, fmt.Errorf("the sharded cache does not store raw responses; disable raw storage before compacting")
+	}
+
+	sharded := &ShardedCache{
+		dir:       filepath.Join(d.getDataPath(), "shards"),
+		redaction: d.redaction,
+		fetchedBy: d.fetchedBy,
+		index: shardIndex{
+			Entries:    map[string]shardEntry{},
+			ShardSizes: map[string]int64{},
+		},
+	}
+	if err := sharded.Initialize(); err != nil {
+		return nil, 0, err
+	}
+
+	keys, err := d.ListIssues()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	copied := 0
+	for _, key := range keys {
+		cached, err := d.GetIssue(key)
+		if err != nil {
+			return nil, copied, fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		if cached.JiraData == nil || cached.JiraData.Key != key {
+			// Skip empty records and alias links (e.g. moved issues)
+			continue
+		}
+		if entry, ok := sharded.index.Entries[key]; ok && entry.ID == cached.JiraData.ID &&
+			entry.FetchedAt.Equal(cached.CacheMetadata.FetchedAt) {
+			// Already compacted
+			continue
+		}
+		if _, err := sharded.appendRecord(cached.JiraData, cached.CacheMetadata); err != nil {
+			return nil, copied, fmt.Errorf("failed to write %s: %w", key, err)
+		}
+		copied++
+	}
+
+	if err := sharded.Close(); err != nil {
+		return nil, copied, err
+	}
+
+	log.Printf("Compacted %d issues into %s", copied, sharded.dir)
+	return sharded, copied, nil
+}
@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestSharded returns an initialized sharded cache in dir
+func newTestSharded(t *testing.T, dir string) *ShardedCache {
+	t.Helper()
+	s := NewSharded(dir, "https://jira.example.com")
+	if err := s.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	return s
+}
+
+func TestShardedAppendOffsets(t *testing.T) {
+	s := newTestSharded(t, t.TempDir())
+
+	// IDs 1001 and 1002 share shard 000001.jsonl
+	for _, issue := range []struct{ id, key, summary string }{
+		{"1001", "PROJ-1", "first"},
+		{"1002", "PROJ-2", "second"},
+		{"1001", "PROJ-1", "rewritten"},
+	} {
+		if _, err := s.WriteIssue(testIssue(issue.id, issue.key, issue.summary), 0); err != nil {
+			t.Fatalf("WriteIssue: %v", err)
+		}
+	}
+
+	first, second := s.index.Entries["PROJ-1"], s.index.Entries["PROJ-2"]
+	if first.Shard != "000001.jsonl" || second.Shard != first.Shard {
+		t.Fatalf("shards = %s, %s, want both 000001.jsonl", first.Shard, second.Shard)
+	}
+	// The rewrite is appended after both earlier records
+	if second.Offset == 0 || first.Offset != second.Offset+second.Length {
+		t.Errorf("offsets = PROJ-1 %d, PROJ-2 %d+%d, want the rewrite after PROJ-2", first.Offset, second.Offset, second.Length)
+	}
+	info, err := os.Stat(filepath.Join(s.dir, first.Shard))
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if size := s.index.ShardSizes[first.Shard]; size != info.Size() || size != first.Offset+first.Length {
+		t.Errorf("indexed size %d, file size %d, want both %d", size, info.Size(), first.Offset+first.Length)
+	}
+
+	cached, err := s.GetIssue("PROJ-1")
+	if err != nil || cached.JiraData.Fields.Summary != "rewritten" {
+		t.Errorf("GetIssue = %+v, %v, want the rewritten record", cached, err)
+	}
+}
+
+func TestShardedReplaysTornTail(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestSharded(t, dir)
+	if _, err := s.WriteIssue(testIssue("1001", "PROJ-1", "indexed"), 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	// Written after the index was saved, then cut short by a crash
+	if _, err := s.WriteIssue(testIssue("1002", "PROJ-2", "replayed"), 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+	shardPath := filepath.Join(s.dir, "000001.jsonl")
+	file, err := os.OpenFile(shardPath, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	file.WriteString(`{"_cache_metadata":{"fetched_at":"2024-01-01T00:00:00Z"},"jira_data":{"id":"1003","key":"PR`)
+	file.Close()
+
+	reopened := newTestSharded(t, dir)
+	if cached, err := reopened.GetIssue("PROJ-2"); err != nil || cached.JiraData.Fields.Summary != "replayed" {
+		t.Errorf("GetIssue(PROJ-2) = %+v, %v, want the replayed record", cached, err)
+	}
+	if reopened.Exists("PROJ-3") {
+		t.Error("torn record was indexed")
+	}
+
+	// The next append overwrites the torn tail
+	if _, err := reopened.WriteIssue(testIssue("1003", "PROJ-3", "complete"), 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+	data, err := os.ReadFile(shardPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"); len(lines) != 3 || strings.Contains(string(data), `"key":"PR"`) {
+		t.Errorf("shard holds %d lines after the append, want 3 complete records:\n%s", len(lines), data)
+	}
+	for _, key := range []string{"PROJ-1", "PROJ-2", "PROJ-3"} {
+		if _, err := reopened.GetIssue(key); err != nil {
+			t.Errorf("GetIssue(%s): %v", key, err)
+		}
+	}
+}
+
+func TestCompactIsIdempotent(t *testing.T) {
+	d := newTestCache(t)
+	for _, issue := range []struct{ id, key string }{{"1001", "PROJ-1"}, {"1002", "PROJ-2"}} {
+		if _, err := d.WriteIssue(testIssue(issue.id, issue.key, "summary"), 0); err != nil {
+			t.Fatalf("WriteIssue: %v", err)
+		}
+	}
+
+	sharded, copied, err := d.Compact()
+	if err != nil || copied != 2 {
+		t.Fatalf("Compact = %d, %v, want 2 issues copied", copied, err)
+	}
+	size := sharded.index.ShardSizes["000001.jsonl"]
+
+	again, copied, err := d.Compact()
+	if err != nil || copied != 0 {
+		t.Fatalf("second Compact = %d, %v, want nothing copied", copied, err)
+	}
+	if again.index.ShardSizes["000001.jsonl"] != size {
+		t.Errorf("shard grew from %d to %d bytes on a repeated Compact", size, again.index.ShardSizes["000001.jsonl"])
+	}
+
+	// A newer fetch is copied on the next run
+	if _, err := d.WriteIssue(testIssue("1001", "PROJ-1", "updated"), 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+	if _, copied, err := d.Compact(); err != nil || copied != 1 {
+		t.Errorf("Compact after an update = %d, %v, want 1 issue copied", copied, err)
+	}
+}
+
+func TestShardedRedactsWrites(t *testing.T) {
+	s := newTestSharded(t, t.TempDir())
+	if err := s.SetRedaction(RedactionConfig{Fields: map[string]RedactionMode{"*.displayName": RedactDrop}}); err != nil {
+		t.Fatalf("SetRedaction: %v", err)
+	}
+	issue := personalIssue()
+	if _, err := s.WriteIssue(issue, 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+
+	cached, err := s.GetIssue(issue.Key)
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	if cached.JiraData.Fields.Assignee.DisplayName != "" || cached.JiraData.Fields.Reporter.DisplayName != "" {
+		t.Errorf("display names stored: %+v, %+v", cached.JiraData.Fields.Assignee, cached.JiraData.Fields.Reporter)
+	}
+}
+
+func TestCompactRejectsRawStorage(t *testing.T) {
+	d := newTestCache(t)
+	d.SetStoreRaw(true)
+	if _, _, err := d.Compact(); err == nil {
+		t.Error("Compact succeeded with raw storage enabled")
+	}
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SyncState records when a project was last synced and what the most
+// recent scrape did. The sync times and watermark only advance after a
+// scrape without errors; the counts describe the most recent scrape,
+// including one with errors.
+type SyncState struct {
+	Project             string    `json:"project"`
+	LastFullSync        time.Time `json:"last_full_sync,omitzero"`
+	LastIncrementalSync time.Time `json:"last_incremental_sync,omitzero"`
+	IssuesProcessed     int       `json:"issues_processed"`
+	IssuesFetched       int       `json:"issues_fetched"`
+	CacheHits           int       `json:"cache_hits"`
+	Errors              int       `json:"errors"`
+
+	// UpdatedWatermark is the point up to which all issue updates are known
+	// to be cached; incremental scrapes only discover issues updated since
+	UpdatedWatermark time.Time `json:"updated_watermark,omitzero"`
+}
+
+// LastSyncTime returns the most recent full or incremental sync time
+func (s *SyncState) LastSyncTime() time.Time {
+	if s.LastIncrementalSync.After(s.LastFullSync) {
+		return s.LastIncrementalSync
+	}
+	return s.LastFullSync
+}
+
+// SyncStateStore is implemented by caches that persist per-project sync state
+type SyncStateStore interface {
+	LastSync(project string) (*SyncState, error)
+	WriteSyncState(state *SyncState) error
+}
+
+// Ensure DiskCache satisfies the SyncStateStore interface
+var _ SyncStateStore = (*DiskCache)(nil)
+
+// syncStatePath returns the sync state file for a project
+// Format: .data/jira/<hostname>/.sync/<project>.json
+func (d *DiskCache) syncStatePath(project string) string {
+	return filepath.Join(d.getDataPath(), ".sync", project+".json")
+}
+
+// LastSync returns the recorded sync state for a project. A project that has
+// never been synced returns an empty state with zero times.
+func (d *DiskCache) LastSync(project string) (*SyncState, error) {
+	data, err := os.ReadFile(d.syncStatePath(project))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &SyncState{Project: project}, nil
+		}
+		return nil, fmt.Errorf("failed to read sync state: %w", err)
+	}
+
+	var state SyncState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal sync state: %w", err)
+	}
+	return &state, nil
+}
+
+// WriteSyncState persists the sync state for a project
+func (d *DiskCache) WriteSyncState(state *SyncState) error {
+	path := d.syncStatePath(state.Project)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create sync state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal sync state: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write sync state: %w", err)
+	}
+	return nil
+}
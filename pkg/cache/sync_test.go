@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyncStateRoundTrip(t *testing.T) {
+	d := newTestCache(t)
+
+	state, err := d.LastSync("PROJ")
+	if err != nil {
+		t.Fatalf("LastSync: %v", err)
+	}
+	if state.Project != "PROJ" || !state.LastSyncTime().IsZero() {
+		t.Errorf("unsynced state = %+v, want an empty state for PROJ", state)
+	}
+
+	state.LastIncrementalSync = time.Date(2024, 3, 5, 14, 7, 0, 0, time.UTC)
+	state.Errors = 2
+	if err := d.WriteSyncState(state); err != nil {
+		t.Fatalf("WriteSyncState: %v", err)
+	}
+	data, err := os.ReadFile(d.syncStatePath("PROJ"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	for _, unset := range []string{"last_full_sync", "updated_watermark"} {
+		if strings.Contains(string(data), unset) {
+			t.Errorf("zero %s written: %s", unset, data)
+		}
+	}
+
+	got, err := d.LastSync("PROJ")
+	if err != nil {
+		t.Fatalf("LastSync: %v", err)
+	}
+	if !got.LastSyncTime().Equal(state.LastIncrementalSync) || got.Errors != 2 {
+		t.Errorf("LastSync = %+v, want the written state", got)
+	}
+}
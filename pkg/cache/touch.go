@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// Toucher is implemented by caches that can mark an issue as freshly
+// checked without rewriting its content, e.g. after a 304 Not Modified
+type Toucher interface {
+	Touch(key string) error
+}
+
+// Ensure DiskCache satisfies the Toucher interface
+var _ Toucher = (*DiskCache)(nil)
+
+// Touch sets the stored record's fetched_at to now and its fetched_by to
+// this cache's stamp, leaving the issue data untouched. The key index entries are rewritten too so their mtimes, used
+// by ListIssuesFetchedAfter, agree with the new fetch time.
+func (d *DiskCache) Touch(key string) error {
+	cached, err := d.GetIssue(key)
+	if err != nil {
+		return err
+	}
+	if cached.JiraData == nil {
+		return fmt.Errorf("issue %s has no data", key)
+	}
+	id := cached.JiraData.ID
+
+	unlock := d.locks.lock("key:"+key, "id:"+id)
+	defer unlock()
+
+	// Re-read under the write lock in case the issue was rewritten meanwhile
+	path := d.recordPath(cached.JiraData)
+	cached, err = d.readIssueFile(path)
+	if err != nil {
+		return err
+	}
+	cached.CacheMetadata.FetchedAt = time.Time{}
+	cached.CacheMetadata.FetchedBy = ""
+	stampMetadata(&cached.CacheMetadata, d.fetchedBy)
+
+	data, err := d.marshalIssue(cached)
+	if err != nil {
+		return err
+	}
+	if err := d.writeRecord(path, data); err != nil {
+		return err
+	}
+	if err := d.linkKey(key, cached.JiraData); err != nil {
+		return fmt.Errorf("failed to index key %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package history
+
+import (
+	"sort"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// Merge combines changelogs, e.g. a cached one with a freshly fetched one,
+// into a single changelog with each history entry appearing once. Entries
+// are deduplicated by ID, with later changelogs taking precedence, and
+// ordered oldest first by creation time. Entries with unparseable
+// timestamps sort after the rest in their original order.
+func Merge(changelogs ...*models.Changelog) *models.Changelog {
+	byID := make(map[string]int)
+	var merged []models.History
+	for _, changelog := range changelogs {
+		if changelog == nil {
+			continue
+		}
+		for _, h := range changelog.Histories {
+			if h.ID == "" {
+				merged = append(merged, h)
+				continue
+			}
+			if i, ok := byID[h.ID]; ok {
+				merged[i] = h
+				continue
+			}
+			byID[h.ID] = len(merged)
+			merged = append(merged, h)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		ti, errI := models.ParseTime(merged[i].Created)
+		tj, errJ := models.ParseTime(merged[j].Created)
+		switch {
+		case errI != nil:
+			return false
+		case errJ != nil:
+			return true
+		}
+		return ti.Before(tj)
+	})
+
+	return &models.Changelog{
+		MaxResults: len(merged),
+		Total:      len(merged),
+		Histories:  merged,
+	}
+}
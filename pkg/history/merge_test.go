@@ -0,0 +1,73 @@
+package history
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// histories builds a changelog page from (id, created) pairs
+func histories(entries ...[2]string) *models.Changelog {
+	changelog := &models.Changelog{}
+	for _, entry := range entries {
+		changelog.Histories = append(changelog.Histories, models.History{ID: entry[0], Created: entry[1]})
+	}
+	return changelog
+}
+
+func TestMergeOverlappingPages(t *testing.T) {
+	first := histories(
+		[2]string{"1", "2024-01-01T10:00:00.000+0000"},
+		[2]string{"2", "2024-01-02T10:00:00.000+0000"},
+		[2]string{"3", "2024-01-03T10:00:00.000+0000"},
+	)
+	// Overlaps the first page on 2 and 3, out of order
+	second := histories(
+		[2]string{"4", "2024-01-04T10:00:00.000+0000"},
+		[2]string{"3", "2024-01-03T10:00:00.000+0000"},
+		[2]string{"2", "2024-01-02T10:00:00.000+0000"},
+	)
+	// The later copy of an entry wins
+	second.Histories[1].Author = &models.User{Name: "later"}
+
+	merged := Merge(first, second)
+
+	var ids []string
+	for _, h := range merged.Histories {
+		ids = append(ids, h.ID)
+	}
+	if want := []string{"1", "2", "3", "4"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("merged IDs = %v, want %v", ids, want)
+	}
+	if merged.Total != 4 || merged.MaxResults != 4 {
+		t.Errorf("Total, MaxResults = %d, %d, want 4, 4", merged.Total, merged.MaxResults)
+	}
+	if author := merged.Histories[2].Author; author == nil || author.Name != "later" {
+		t.Errorf("entry 3 author = %+v, want the later copy", author)
+	}
+}
+
+func TestMergeKeepsOrderOfEqualAndUnparseableTimes(t *testing.T) {
+	merged := Merge(
+		histories(
+			[2]string{"b", "not a time"},
+			[2]string{"x", "2024-01-01T10:00:00.000+0000"},
+			[2]string{"y", "2024-01-01T10:00:00.000+0000"},
+		),
+		nil,
+		histories(
+			[2]string{"", "2024-01-01T09:00:00.000+0000"},
+			[2]string{"", "2024-01-01T09:00:00.000+0000"},
+		),
+	)
+
+	var ids []string
+	for _, h := range merged.Histories {
+		ids = append(ids, h.ID)
+	}
+	// Entries without IDs are never deduplicated; ties keep input order
+	if want := []string{"", "", "x", "y", "b"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("merged IDs = %q, want %q", ids, want)
+	}
+}
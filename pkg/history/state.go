@@ -0,0 +1,164 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// StateAt reconstructs an issue's fields as they were at time t by starting
+// from the current fields and undoing every changelog entry made after t,
+// newest first. Fields with no recorded history are assumed to have held
+// their current value. Labels, components, fix versions and affects
+// versions are reverted too; a component or version restored by undoing its
+// removal carries only the ID and name the changelog recorded. It returns
+// an error if t is before the issue was created.
+func StateAt(issue *models.IssueWithHistory, t time.Time) (*models.IssueFields, error) {
+	if issue == nil || issue.Fields == nil {
+		return nil, fmt.Errorf("issue has no fields")
+	}
+
+	created, err := models.ParseTime(issue.Fields.Created)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse created time: %w", err)
+	}
+	if t.Before(created) {
+		return nil, fmt.Errorf("issue %s did not exist at %s", issue.Key, t.Format(time.RFC3339))
+	}
+
+	// Work on a deep copy so the cached issue is untouched
+	data, err := json.Marshal(issue.Fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy fields: %w", err)
+	}
+	var fields models.IssueFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to copy fields: %w", err)
+	}
+
+	type entry struct {
+		at      time.Time
+		created string
+		items   []models.HistoryItem
+	}
+	var entries []entry
+	if issue.Changelog != nil {
+		for _, h := range issue.Changelog.Histories {
+			at, err := models.ParseTime(h.Created)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse history %s time: %w", h.ID, err)
+			}
+			entries = append(entries, entry{at: at, created: h.Created, items: h.Items})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].at.After(entries[j].at)
+	})
+
+	fields.Updated = issue.Fields.Created
+	for _, e := range entries {
+		if !e.at.After(t) {
+			// Newest change still in effect at t
+			fields.Updated = e.created
+			break
+		}
+		// Undo items in reverse order within the entry
+		for i := len(e.items) - 1; i >= 0; i-- {
+			revertItem(&fields, e.items[i])
+		}
+	}
+
+	return &fields, nil
+}
+
+// revertItem sets a field back to the "from" side of a change
+func revertItem(fields *models.IssueFields, item models.HistoryItem) {
+	from := deref(item.From)
+	fromString := deref(item.FromString)
+
+	switch strings.ToLower(item.Field) {
+	case "summary":
+		fields.Summary = fromString
+	case "description":
+		fields.Description = models.RichText{Text: fromString}
+	case "status":
+		fields.Status = &models.Status{ID: from, Name: fromString}
+	case "priority":
+		if item.From == nil && item.FromString == nil {
+			fields.Priority = nil
+		} else {
+			fields.Priority = &models.Priority{ID: from, Name: fromString}
+		}
+	case "issuetype":
+		fields.IssueType = &models.IssueType{ID: from, Name: fromString}
+	case "assignee":
+		if item.From == nil && item.FromString == nil {
+			fields.Assignee = nil
+		} else {
+			fields.Assignee = &models.User{Name: from, Key: from, DisplayName: fromString}
+		}
+	case "labels":
+		// Label changes record the whole space-separated list
+		fields.Labels = strings.Fields(fromString)
+	case "component":
+		fields.Components = revertComponent(fields.Components, item)
+	case "fix version":
+		fields.FixVersions = revertVersion(fields.FixVersions, item)
+	case "version":
+		fields.AffectsVersions = revertVersion(fields.AffectsVersions, item)
+	case "resolution":
+		// The previous resolution date is not recorded; an issue that was
+		// unresolved before the change simply has none
+		if item.From == nil && item.FromString == nil {
+			fields.ResolutionDate = nil
+		}
+	}
+}
+
+// revertComponent undoes a component change, which records one component
+// added (to) or removed (from)
+func revertComponent(components []models.Component, item models.HistoryItem) []models.Component {
+	if item.To != nil {
+		kept := make([]models.Component, 0, len(components))
+		for _, c := range components {
+			if c.ID != *item.To {
+				kept = append(kept, c)
+			}
+		}
+		components = kept
+	}
+	if item.From != nil {
+		components = append(components, models.Component{ID: *item.From, Name: deref(item.FromString)})
+	}
+	return components
+}
+
+// revertVersion undoes a version change, which records one version added
+// (to) or removed (from)
+func revertVersion(versions []models.Version, item models.HistoryItem) []models.Version {
+	if item.To != nil {
+		kept := make([]models.Version, 0, len(versions))
+		for _, v := range versions {
+			if v.ID != *item.To {
+				kept = append(kept, v)
+			}
+		}
+		versions = kept
+	}
+	if item.From != nil {
+		versions = append(versions, models.Version{ID: *item.From, Name: deref(item.FromString)})
+	}
+	return versions
+}
+
+// deref returns the string a pointer refers to, or "" for nil
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
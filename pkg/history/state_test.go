@@ -0,0 +1,104 @@
+package history
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+func strPtr(s string) *string { return &s }
+
+// multiValueIssue returns an issue whose labels, components and versions
+// changed on 2024-02-01 and 2024-03-01
+func multiValueIssue() *models.IssueWithHistory {
+	issue := &models.IssueWithHistory{}
+	issue.Key = "PROJ-1"
+	issue.Fields = &models.IssueFields{
+		Created:         "2024-01-01T00:00:00.000+0000",
+		Labels:          []string{"backend", "urgent"},
+		Components:      []models.Component{{ID: "20", Name: "API"}},
+		FixVersions:     []models.Version{{ID: "31", Name: "2.0", Released: true}},
+		AffectsVersions: []models.Version{{ID: "30", Name: "1.0"}},
+	}
+	issue.Changelog = &models.Changelog{Histories: []models.History{
+		{ID: "1", Created: "2024-02-01T00:00:00.000+0000", Items: []models.HistoryItem{
+			{Field: "labels", FromString: strPtr("backend"), ToString: strPtr("backend ui")},
+			{Field: "Component", From: strPtr("10"), FromString: strPtr("UI")},
+			{Field: "Fix Version", To: strPtr("30"), ToString: strPtr("1.0")},
+		}},
+		{ID: "2", Created: "2024-03-01T00:00:00.000+0000", Items: []models.HistoryItem{
+			{Field: "labels", FromString: strPtr("backend ui"), ToString: strPtr("backend urgent")},
+			{Field: "Component", To: strPtr("20"), ToString: strPtr("API")},
+			{Field: "Fix Version", From: strPtr("30"), FromString: strPtr("1.0"), To: strPtr("31"), ToString: strPtr("2.0")},
+			{Field: "Version", To: strPtr("30"), ToString: strPtr("1.0")},
+		}},
+	}}
+	return issue
+}
+
+func TestStateAtRevertsMultiValueFields(t *testing.T) {
+	tests := []struct {
+		name            string
+		at              string
+		labels          []string
+		components      []models.Component
+		fixVersions     []models.Version
+		affectsVersions []models.Version
+	}{
+		{
+			name:            "before any change",
+			at:              "2024-01-15T00:00:00Z",
+			labels:          []string{"backend"},
+			components:      []models.Component{{ID: "10", Name: "UI"}},
+			fixVersions:     []models.Version{},
+			affectsVersions: []models.Version{},
+		},
+		{
+			name:            "between changes",
+			at:              "2024-02-15T00:00:00Z",
+			labels:          []string{"backend", "ui"},
+			components:      []models.Component{},
+			fixVersions:     []models.Version{{ID: "30", Name: "1.0"}},
+			affectsVersions: []models.Version{},
+		},
+		{
+			name:            "current",
+			at:              "2024-04-01T00:00:00Z",
+			labels:          []string{"backend", "urgent"},
+			components:      []models.Component{{ID: "20", Name: "API"}},
+			fixVersions:     []models.Version{{ID: "31", Name: "2.0", Released: true}},
+			affectsVersions: []models.Version{{ID: "30", Name: "1.0"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at, err := time.Parse(time.RFC3339, tt.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			issue := multiValueIssue()
+			fields, err := StateAt(issue, at)
+			if err != nil {
+				t.Fatalf("StateAt: %v", err)
+			}
+			if !reflect.DeepEqual(fields.Labels, tt.labels) {
+				t.Errorf("labels = %q, want %q", fields.Labels, tt.labels)
+			}
+			if !reflect.DeepEqual(fields.Components, tt.components) {
+				t.Errorf("components = %+v, want %+v", fields.Components, tt.components)
+			}
+			if !reflect.DeepEqual(fields.FixVersions, tt.fixVersions) {
+				t.Errorf("fix versions = %+v, want %+v", fields.FixVersions, tt.fixVersions)
+			}
+			if !reflect.DeepEqual(fields.AffectsVersions, tt.affectsVersions) {
+				t.Errorf("affects versions = %+v, want %+v", fields.AffectsVersions, tt.affectsVersions)
+			}
+			if len(issue.Fields.Components) != 1 || issue.Fields.Components[0].ID != "20" {
+				t.Errorf("StateAt modified the issue's components: %+v", issue.Fields.Components)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package history
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// Transition is a single status change parsed from the changelog
+type Transition struct {
+	From string
+	To   string
+	By   *models.User
+	At   time.Time
+}
+
+// Transitions extracts the status transitions from an issue's changelog,
+// sorted oldest first. Histories with unparseable timestamps are skipped.
+func Transitions(issue *models.IssueWithHistory) []Transition {
+	if issue == nil || issue.Changelog == nil {
+		return nil
+	}
+
+	var transitions []Transition
+	for _, h := range issue.Changelog.Histories {
+		at, err := models.ParseTime(h.Created)
+		if err != nil {
+			continue
+		}
+		for _, item := range h.Items {
+			if item.Field != "status" {
+				continue
+			}
+			transitions = append(transitions, Transition{
+				From: deref(item.FromString),
+				To:   deref(item.ToString),
+				By:   h.Author,
+				At:   at,
+			})
+		}
+	}
+
+	sort.SliceStable(transitions, func(i, j int) bool {
+		return transitions[i].At.Before(transitions[j].At)
+	})
+	return transitions
+}
+
+// StatusDurations computes the cumulative time an issue spent in each status.
+// Time is counted from issue creation through each status change to the
+// resolution date, or to now for unresolved issues. Issues that cycle
+// through a status several times (e.g. reopened) accumulate all visits.
+// A resolution date before the last transition (e.g. a stale date left on
+// a reopened issue) ends the count at that transition, so the final status
+// is credited no time rather than time up to now.
+func StatusDurations(issue *models.IssueWithHistory) map[string]time.Duration {
+	durations := make(map[string]time.Duration)
+	if issue == nil || issue.Fields == nil {
+		return durations
+	}
+
+	created, err := models.ParseTime(issue.Fields.Created)
+	if err != nil {
+		return durations
+	}
+
+	changes := Transitions(issue)
+
+	// The initial status is whatever the first transition moved away from;
+	// with no transitions the issue has always been in its current status.
+	current := ""
+	if len(changes) > 0 {
+		current = changes[0].From
+	} else if issue.Fields.Status != nil {
+		current = issue.Fields.Status.Name
+	}
+
+	since := created
+	for _, change := range changes {
+		if change.At.After(since) {
+			durations[current] += change.At.Sub(since)
+			since = change.At
+		}
+		current = change.To
+	}
+
+	end := time.Now()
+	if issue.Fields.ResolutionDate != nil {
+		if resolved, err := models.ParseTime(*issue.Fields.ResolutionDate); err == nil {
+			end = resolved
+			if resolved.Before(since) {
+				end = since
+			}
+		}
+	}
+	if end.After(since) {
+		durations[current] += end.Sub(since)
+	}
+
+	return durations
+}
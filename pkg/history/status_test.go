@@ -0,0 +1,63 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// resolvedIssue returns an issue created 2024-01-01 that moved from Open to
+// In Progress on 01-02 and to Done on 01-04, resolved at resolutionDate
+func resolvedIssue(resolutionDate string) *models.IssueWithHistory {
+	issue := &models.IssueWithHistory{}
+	issue.Key = "PROJ-1"
+	issue.Fields = &models.IssueFields{
+		Created:        "2024-01-01T00:00:00.000+0000",
+		Status:         &models.Status{Name: "Done"},
+		ResolutionDate: &resolutionDate,
+	}
+	issue.Changelog = &models.Changelog{Histories: []models.History{
+		{ID: "1", Created: "2024-01-02T00:00:00.000+0000", Items: []models.HistoryItem{
+			{Field: "status", FromString: strPtr("Open"), ToString: strPtr("In Progress")},
+		}},
+		{ID: "2", Created: "2024-01-04T00:00:00.000+0000", Items: []models.HistoryItem{
+			{Field: "status", FromString: strPtr("In Progress"), ToString: strPtr("Done")},
+		}},
+	}}
+	return issue
+}
+
+func TestStatusDurations(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		name       string
+		resolution string
+		want       map[string]time.Duration
+	}{
+		{
+			name:       "resolved after last transition",
+			resolution: "2024-01-05T00:00:00.000+0000",
+			want:       map[string]time.Duration{"Open": day, "In Progress": 2 * day, "Done": day},
+		},
+		{
+			name:       "resolved before last transition",
+			resolution: "2024-01-03T00:00:00.000+0000",
+			want:       map[string]time.Duration{"Open": day, "In Progress": 2 * day},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := StatusDurations(resolvedIssue(tt.resolution))
+			if len(got) != len(tt.want) {
+				t.Errorf("durations = %v, want %v", got, tt.want)
+			}
+			for status, want := range tt.want {
+				if got[status] != want {
+					t.Errorf("%s = %v, want %v", status, got[status], want)
+				}
+			}
+		})
+	}
+}
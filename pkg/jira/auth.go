@@ -0,0 +1,120 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TokenProvider supplies the token for each request, for credentials that
+// expire during long scrapes (e.g. OAuth access tokens). It replaces the
+// static token given to New.
+type TokenProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenInvalidator is implemented by token providers that can discard a
+// cached token. The client calls it when a request is rejected with 401,
+// before retrying once with a fresh token.
+type TokenInvalidator interface {
+	Invalidate()
+}
+
+// TokenFunc fetches a new token and the time it expires
+type TokenFunc func(ctx context.Context) (token string, expiresAt time.Time, err error)
+
+// CachingTokenProvider reuses a fetched token until shortly before it
+// expires, then fetches a new one. It is safe for concurrent use.
+type CachingTokenProvider struct {
+	fetch TokenFunc
+	skew  time.Duration // Refresh this long before expiry
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// Ensure CachingTokenProvider satisfies the token provider interfaces
+var (
+	_ TokenProvider    = (*CachingTokenProvider)(nil)
+	_ TokenInvalidator = (*CachingTokenProvider)(nil)
+)
+
+// NewCachingTokenProvider creates a provider that caches the tokens
+// returned by fetch, refreshing them a minute before they expire. A zero
+// expiry means the token is cached until invalidated.
+func NewCachingTokenProvider(fetch TokenFunc) *CachingTokenProvider {
+	return &CachingTokenProvider{
+		fetch: fetch,
+		skew:  time.Minute,
+	}
+}
+
+// Token returns the cached token, fetching a new one if there is none or it
+// is about to expire
+func (p *CachingTokenProvider) Token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && (p.expiresAt.IsZero() || time.Now().Add(p.skew).Before(p.expiresAt)) {
+		return p.token, nil
+	}
+
+	token, expiresAt, err := p.fetch(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch token: %w", err)
+	}
+	if token == "" {
+		return "", fmt.Errorf("failed to fetch token: empty token")
+	}
+	p.token = token
+	p.expiresAt = expiresAt
+	return token, nil
+}
+
+// Invalidate discards the cached token so the next call fetches a new one
+func (p *CachingTokenProvider) Invalidate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.token = ""
+	p.expiresAt = time.Time{}
+}
+
+// SetTokenProvider makes the client fetch its token from provider for each
+// request instead of using a static token. Nil restores the static token.
+func (c *Client) SetTokenProvider(provider TokenProvider) {
+	c.tokenProvider = provider
+}
+
+// setAuth adds the authentication header to a request, fetching the token
+// from the token provider when one is set
+func (c *Client) setAuth(req *http.Request) error {
+	token := c.token
+	if c.tokenProvider != nil {
+		var err error
+		token, err = c.tokenProvider.Token(req.Context())
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.email != "" {
+		req.SetBasicAuth(c.email, token)
+		return nil
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// refreshToken invalidates the provider's cached token after a 401,
+// reporting whether a retry with a fresh token is worthwhile
+func (c *Client) refreshToken() bool {
+	invalidator, ok := c.tokenProvider.(TokenInvalidator)
+	if !ok {
+		return false
+	}
+	invalidator.Invalidate()
+	return true
+}
@@ -0,0 +1,116 @@
+package jira
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting JIRA while the circuit
+// breaker is open after repeated failures
+var ErrCircuitOpen = errors.New("circuit breaker open: too many consecutive JIRA failures")
+
+// BreakerState is the state of the client's circuit breaker
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // Requests flow normally
+	BreakerOpen     BreakerState = "open"      // Requests fail fast until the cooldown ends
+	BreakerHalfOpen BreakerState = "half-open" // One trial request decides whether to close
+)
+
+// circuitBreaker stops requests to a failing server. Transport errors and
+// 5xx responses count as failures; after threshold consecutive failures the
+// breaker opens for cooldown, then lets a single trial request through.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int // Consecutive failures that open the breaker; 0 disables it
+	cooldown  time.Duration
+
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	trial    bool // A half-open trial request is in flight
+
+	logf func(level slog.Level, format string, args ...any) // The client's logf
+}
+
+// allow reports whether a request may be sent now
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.logf(slog.LevelInfo, "Circuit breaker half-open: sending a trial request")
+		b.state = BreakerHalfOpen
+		b.trial = true
+		return nil
+	case BreakerHalfOpen:
+		if b.trial {
+			return ErrCircuitOpen
+		}
+		b.trial = true
+	}
+	return nil
+}
+
+// record updates the breaker with the outcome of an allowed request
+func (b *circuitBreaker) record(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.trial = false
+	if ok {
+		if b.state != BreakerClosed {
+			b.logf(slog.LevelInfo, "Circuit breaker closed: JIRA is responding again")
+		}
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		if b.state != BreakerOpen {
+			b.logf(slog.LevelWarn, "Circuit breaker open after %d consecutive failures; pausing requests for %v", b.failures, b.cooldown)
+		}
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// SetCircuitBreaker configures the circuit breaker: after threshold
+// consecutive failed requests (transport errors or 5xx), requests fail fast
+// with ErrCircuitOpen for cooldown before a trial request is allowed. The
+// default is 10 failures and a one minute cooldown; a threshold of 0
+// disables the breaker.
+func (c *Client) SetCircuitBreaker(threshold int, cooldown time.Duration) {
+	c.breaker.mu.Lock()
+	defer c.breaker.mu.Unlock()
+	c.breaker.threshold = threshold
+	c.breaker.cooldown = cooldown
+	c.breaker.state = BreakerClosed
+	c.breaker.failures = 0
+	c.breaker.trial = false
+}
+
+// CircuitBreakerState returns the breaker's state and the current count of
+// consecutive failures
+func (c *Client) CircuitBreakerState() (BreakerState, int) {
+	c.breaker.mu.Lock()
+	defer c.breaker.mu.Unlock()
+	return c.breaker.state, c.breaker.failures
+}
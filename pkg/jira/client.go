@@ -1,121 +1,516 @@
 package jira
 
 import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/jctanner/go-jira-scraper/pkg/jql"
 	"github.com/jctanner/go-jira-scraper/pkg/models"
 )
 
+// DefaultOrderBy is the ORDER BY clause used for discovery when none is given
+const DefaultOrderBy = "updated DESC"
+
+// ValidateOrderBy checks that an ORDER BY clause is a comma-separated list of
+// field names with optional ASC/DESC directions
+func ValidateOrderBy(orderBy string) error {
+	return jql.ValidateOrderBy(orderBy)
+}
+
+// ErrNotModified is returned when a conditional request reports that the
+// resource has not changed since the supplied ETag / Last-Modified value
+var ErrNotModified = errors.New("not modified")
+
+// ErrResponseTooLarge is returned when a response body exceeds the limit set
+// with SetMaxResponseSize
+var ErrResponseTooLarge = errors.New("response body exceeds maximum size")
+
+// APIError is returned when JIRA responds with a non-retryable error status
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// isStatus reports whether err wraps an APIError with the given status code
+func isStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// IsPermissionDenied reports whether err is a 403 response, e.g. an issue
+// hidden by a security level that still shows up in search results
+func IsPermissionDenied(err error) bool {
+	return isStatus(err, http.StatusForbidden)
+}
+
+// Doer executes HTTP requests. *http.Client satisfies it; tests and callers
+// can substitute their own implementation via SetDoer.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
 // Client handles interactions with the JIRA API
 type Client struct {
 	baseURL    string
-	httpClient *http.Client
+	httpClient *http.Client // Underlying client; TLS and timeout settings apply here
+	doer       Doer         // Executes requests; defaults to httpClient
 	token      string
+	email      string // Set for JIRA Cloud basic auth (email + API token)
 	batchSize  int
+
+	tokenProvider TokenProvider // Supplies the token per request; nil uses token
+
+	requestDelay time.Duration // Politeness delay between sequential requests
+	maxRetries   int           // Retries after a failed attempt
+
+	// Per-request deadlines; zero means no deadline
+	apiTimeout      time.Duration // JSON API calls
+	downloadTimeout time.Duration // Attachment and other binary downloads
+
+	retryableStatuses map[int]bool  // Non-429 statuses retried with backoff
+	inFlight          chan struct{} // Semaphore capping concurrent HTTP requests; nil means unlimited
+	breaker           *circuitBreaker
+	maxResponseSize   int64 // Largest response body read into memory; 0 means unlimited
+
+	checkpoint      DiscoveryCheckpoint // Saves search progress; nil disables it
+	checkpointEvery int                 // Pages between checkpoint saves
+
+	searchProgress func(fetched, total int) // Called after each search page; may be nil
+
+	logger *slog.Logger // Structured request logging; nil logs plain text
+
+	// Tracing hooks; nil when unset
+	onRequest  func(method, url string, headers http.Header)
+	onResponse func(status int, headers http.Header, body []byte)
+
+	// Adaptive batch sizing (AIMD): halved on 429, grown by one after a run
+	// of successful requests, never exceeding batchSize
+	mu                 sync.Mutex
+	effectiveBatchSize int
+	successStreak      int
+
+	// Adaptive delay between search pages: raised on throttling or a
+	// Retry-After header, decayed back toward requestDelay on success
+	pageDelay    time.Duration
+	maxPageDelay time.Duration // Ceiling for pageDelay; zero keeps it fixed at requestDelay
+
+	sleep func(time.Duration) // Waits between attempts and pages; replaced in tests
 }
 
+// batchRampUpAfter is the number of consecutive successful requests needed
+// before the effective batch size grows by one
+const batchRampUpAfter = 5
+
 // New creates a new JIRA client
 func New(baseURL, token string) *Client {
-	return &Client{
+	c := &Client{
 		baseURL: baseURL,
 		token:   token,
 		batchSize: 10, // Default to 10 for JIRA rate limit compatibility
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		effectiveBatchSize: 10,
+		requestDelay: 500 * time.Millisecond,
+		pageDelay: 500 * time.Millisecond,
+		maxPageDelay: 30 * time.Second,
+		maxRetries: 3,
+		retryableStatuses: map[int]bool{500: true, 502: true, 503: true, 504: true},
+		apiTimeout: 30 * time.Second,
+		maxResponseSize: 64 << 20,
+		breaker: &circuitBreaker{threshold: 10, cooldown: time.Minute, state: BreakerClosed},
+		// Timeouts are applied per request via contexts so downloads are
+		// not bound by the API timeout
+		httpClient: &http.Client{},
 	}
+	c.doer = c.httpClient
+	c.sleep = time.Sleep
+	c.breaker.logf = c.logf
+	return c
+}
+
+// SetDoer replaces the component that executes HTTP requests, e.g. with a
+// fake returning canned responses in tests. Transport settings such as
+// SetTLSConfig only affect the default *http.Client.
+func (c *Client) SetDoer(doer Doer) {
+	c.doer = doer
+}
+
+// SetAPITimeout sets the deadline for each JSON API request attempt
+// (default 30s). Zero disables it.
+func (c *Client) SetAPITimeout(timeout time.Duration) {
+	c.apiTimeout = timeout
+}
+
+// SetDownloadTimeout sets the deadline for each attachment download
+// attempt, including reading the body (default: none). Zero disables it.
+func (c *Client) SetDownloadTimeout(timeout time.Duration) {
+	c.downloadTimeout = timeout
+}
+
+// DiscoveryCheckpoint persists the progress of a paginated search so an
+// interrupted discovery of a huge project can resume where it stopped.
+// AppendDiscovery is passed only the issues found since the previous save;
+// LoadDiscovery returns all of them. cache.DiskCache implements it.
+type DiscoveryCheckpoint interface {
+	LoadDiscovery(jql string) (startAt int, issues []*models.Issue, ok bool)
+	AppendDiscovery(jql string, startAt int, issues []*models.Issue) error
+	ClearDiscovery(jql string) error
+}
+
+// SetDiscoveryCheckpoint enables resumable searches, saving progress to
+// checkpoint every `every` pages (default 10). nil disables it.
+func (c *Client) SetDiscoveryCheckpoint(checkpoint DiscoveryCheckpoint, every int) {
+	if every <= 0 {
+		every = 10
+	}
+	c.checkpoint = checkpoint
+	c.checkpointEvery = every
+}
+
+// SetMaxResponseSize caps the size of API response bodies read into memory
+// (default 64MB), guarding against pathological responses. Larger bodies
+// fail with ErrResponseTooLarge. Zero removes the cap.
+func (c *Client) SetMaxResponseSize(size int64) {
+	c.maxResponseSize = size
+}
+
+// requestContext returns a context with the given deadline, or without one
+// when timeout is zero
+func requestContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), timeout)
 }
 
 // SetBatchSize sets the batch size for search queries
 func (c *Client) SetBatchSize(size int) {
 	if size > 0 && size <= 100 {
+		c.mu.Lock()
 		c.batchSize = size
+		c.effectiveBatchSize = size
+		c.successStreak = 0
+		c.mu.Unlock()
 	}
 }
 
+// EffectiveBatchSize returns the batch size currently used for searches,
+// which shrinks below the configured size while the server is throttling
+func (c *Client) EffectiveBatchSize() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.effectiveBatchSize
+}
+
+// recordThrottled halves the effective batch size and doubles the page
+// delay after a 429
+func (c *Client) recordThrottled() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.successStreak = 0
+	if c.effectiveBatchSize > 1 {
+		c.effectiveBatchSize /= 2
+		c.logf(slog.LevelWarn, "Throttled: reducing batch size to %d", c.effectiveBatchSize)
+	}
+	c.raisePageDelay(max(2*c.pageDelay, time.Second))
+}
+
+// recordRetryAfter raises the page delay to at least the server's
+// requested wait, for Retry-After headers on otherwise successful responses
+func (c *Client) recordRetryAfter(wait time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.raisePageDelay(wait)
+}
+
+// raisePageDelay sets the page delay to at least delay, capped at
+// maxPageDelay. The caller must hold c.mu.
+func (c *Client) raisePageDelay(delay time.Duration) {
+	if c.maxPageDelay <= 0 || delay <= c.pageDelay {
+		return
+	}
+	c.pageDelay = min(delay, c.maxPageDelay)
+	c.logf(slog.LevelWarn, "Throttled: increasing delay between search pages to %v", c.pageDelay)
+}
+
+// recordSuccess grows the effective batch size back toward the configured
+// size after a run of successful requests, and decays the page delay by a
+// quarter toward the configured request delay
+func (c *Client) recordSuccess() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pageDelay > c.requestDelay {
+		c.pageDelay = max(c.pageDelay-c.pageDelay/4, c.requestDelay)
+	}
+	if c.effectiveBatchSize >= c.batchSize {
+		return
+	}
+	c.successStreak++
+	if c.successStreak >= batchRampUpAfter {
+		c.successStreak = 0
+		c.effectiveBatchSize++
+	}
+}
+
+// SetRequestDelay sets the politeness delay between sequential requests,
+// such as search pages or issue fetches. Zero disables the delay.
+func (c *Client) SetRequestDelay(delay time.Duration) {
+	if delay >= 0 {
+		c.mu.Lock()
+		c.requestDelay = delay
+		c.pageDelay = delay
+		c.mu.Unlock()
+	}
+}
+
+// SetMaxPageDelay caps how far the delay between search pages may grow
+// while the server is throttling or sending Retry-After (default 30s). Zero
+// keeps the delay fixed at the request delay.
+func (c *Client) SetMaxPageDelay(delay time.Duration) {
+	if delay >= 0 {
+		c.mu.Lock()
+		c.maxPageDelay = delay
+		c.pageDelay = c.requestDelay
+		c.mu.Unlock()
+	}
+}
+
+// PageDelay returns the current delay between search pages
+func (c *Client) PageDelay() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pageDelay
+}
+
+// RequestDelay returns the politeness delay between sequential requests
+func (c *Client) RequestDelay() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.requestDelay
+}
+
+// SetMaxRetries sets how many times a failed request (transport error,
+// 429, or retryable status) is retried (default 3)
+func (c *Client) SetMaxRetries(n int) {
+	if n >= 0 {
+		c.maxRetries = n
+	}
+}
+
+// SetMaxConcurrency caps the number of HTTP requests in flight at once
+// across all goroutines using the client, independent of how many scraper
+// workers call in. Zero or a negative value removes the cap. It should be
+// called before the client is shared between goroutines.
+func (c *Client) SetMaxConcurrency(n int) {
+	if n <= 0 {
+		c.inFlight = nil
+		return
+	}
+	c.inFlight = make(chan struct{}, n)
+}
+
+// acquireSlot blocks until an in-flight request slot is available
+func (c *Client) acquireSlot() {
+	if c.inFlight != nil {
+		c.inFlight <- struct{}{}
+	}
+}
+
+// releaseSlot frees an in-flight request slot
+func (c *Client) releaseSlot() {
+	if c.inFlight != nil {
+		<-c.inFlight
+	}
+}
+
+// SetRetryableStatuses sets which HTTP statuses, besides 429, are retried
+// with exponential backoff. The default is 500, 502, 503 and 504; pass an
+// empty list to fail immediately on all server errors.
+func (c *Client) SetRetryableStatuses(statuses []int) {
+	c.retryableStatuses = make(map[int]bool, len(statuses))
+	for _, status := range statuses {
+		c.retryableStatuses[status] = true
+	}
+}
+
+// SetBasicAuth switches to basic authentication with an account email and
+// API token, as used by JIRA Cloud, instead of a bearer token
+func (c *Client) SetBasicAuth(email, apiToken string) {
+	c.email = email
+	c.token = apiToken
+}
+
 // doRequest performs an HTTP request with authentication and retry logic
 func (c *Client) doRequest(method, path string, query url.Values) ([]byte, error) {
-	return c.doRequestWithRetry(method, path, query, 3)
+	body, _, err := c.doRequestWithRetry(method, path, query, nil, c.maxRetries)
+	return body, err
 }
 
-// doRequestWithRetry performs an HTTP request with retry logic for rate limits
-func (c *Client) doRequestWithRetry(method, path string, query url.Values, maxRetries int) ([]byte, error) {
+// doRequestWithHeaders performs an HTTP request with extra request headers and
+// also returns the response headers
+func (c *Client) doRequestWithHeaders(method, path string, query url.Values, header http.Header) ([]byte, http.Header, error) {
+	return c.doRequestWithRetry(method, path, query, header, c.maxRetries)
+}
+
+// doRequestWithRetry performs an HTTP request with retry logic for rate limits.
+// A 304 response is reported as ErrNotModified along with the response headers.
+func (c *Client) doRequestWithRetry(method, path string, query url.Values, header http.Header, maxRetries int) ([]byte, http.Header, error) {
 	reqURL := c.baseURL + path
 	if len(query) > 0 {
 		reqURL += "?" + query.Encode()
 	}
 
-	log.Printf("Request: %s %s", method, reqURL)
+	c.logRequest(slog.LevelInfo, fmt.Sprintf("Request: %s %s", method, reqURL),
+		slog.String("method", method), slog.String("url", reqURL))
 
 	var lastErr error
-	
+	refreshed := false // Whether the token was refreshed after a 401
+
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		if attempt > 0 {
-			log.Printf("Retry attempt %d/%d", attempt, maxRetries)
+			c.logRequest(slog.LevelInfo, fmt.Sprintf("Retry attempt %d/%d", attempt, maxRetries),
+				slog.String("method", method), slog.String("url", reqURL), slog.Int("attempt", attempt))
+		}
+
+		// Take an in-flight slot, held until the body is read, before
+		// starting the deadline so waiting for one does not count against
+		// the API timeout
+		c.acquireSlot()
+		if err := c.breaker.allow(); err != nil {
+			c.releaseSlot()
+			return nil, nil, err
 		}
 
-		req, err := http.NewRequest(method, reqURL, nil)
+		ctx, cancel := requestContext(c.apiTimeout)
+		req, err := http.NewRequestWithContext(ctx, method, reqURL, nil)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
+			c.releaseSlot()
+			cancel()
+			c.breaker.record(true)
+			return nil, nil, fmt.Errorf("failed to create request: %w", err)
 		}
 
 		// Set headers
-		req.Header.Set("Authorization", "Bearer "+c.token)
+		if err := c.setAuth(req); err != nil {
+			c.releaseSlot()
+			cancel()
+			// Settle the admitted request so a half-open trial is not
+			// left in flight
+			c.breaker.record(false)
+			return nil, nil, fmt.Errorf("failed to authenticate request: %w", err)
+		}
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Accept", "application/json")
+		// Requested explicitly (rather than left to http.Transport) so
+		// custom Doers get compressed responses too; see decodeBody
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+		for name, values := range header {
+			for _, value := range values {
+				req.Header.Add(name, value)
+			}
+		}
+
+		c.traceRequest(req)
 
 		// Execute request
-		resp, err := c.httpClient.Do(req)
+		resp, err := c.doer.Do(req)
 		if err != nil {
+			c.releaseSlot()
+			cancel()
+			c.breaker.record(false)
 			lastErr = fmt.Errorf("request failed: %w", err)
 			if attempt < maxRetries {
 				waitTime := time.Duration(1<<uint(attempt+1)) * time.Second
-				log.Printf("Request error. Waiting %v before retry...", waitTime)
-				time.Sleep(waitTime)
+				c.logRequest(slog.LevelWarn, fmt.Sprintf("Request error. Waiting %v before retry...", waitTime),
+					slog.String("method", method), slog.String("url", reqURL), slog.Int("attempt", attempt),
+					waitAttr(waitTime), slog.String("error", err.Error()))
+				c.sleep(waitTime)
 			}
 			continue
 		}
 
-		// Read response body
-		body, err := io.ReadAll(resp.Body)
+		// Read response body, up to one byte past the cap to detect
+		// overflow. The cap applies after decompression.
+		reader, err := decodeBody(resp)
+		if err == nil && c.maxResponseSize > 0 {
+			reader = io.LimitReader(reader, c.maxResponseSize+1)
+		}
+		var body []byte
+		if err == nil {
+			body, err = io.ReadAll(reader)
+		}
 		resp.Body.Close()
+		c.releaseSlot()
+		cancel()
+		c.breaker.record(err == nil && resp.StatusCode < 500)
+		if err == nil && c.onResponse != nil {
+			c.onResponse(resp.StatusCode, resp.Header, body)
+		}
+		if err == nil && c.maxResponseSize > 0 && int64(len(body)) > c.maxResponseSize {
+			return nil, nil, fmt.Errorf("%s %s: %w (%d bytes)", method, path, ErrResponseTooLarge, c.maxResponseSize)
+		}
 		if err != nil {
 			lastErr = fmt.Errorf("failed to read response body: %w", err)
 			if attempt < maxRetries {
 				waitTime := time.Duration(1<<uint(attempt+1)) * time.Second
-				log.Printf("Read error. Waiting %v before retry...", waitTime)
-				time.Sleep(waitTime)
+				c.logRequest(slog.LevelWarn, fmt.Sprintf("Read error. Waiting %v before retry...", waitTime),
+					slog.String("method", method), slog.String("url", reqURL), slog.Int("attempt", attempt),
+					slog.Int("status", resp.StatusCode), waitAttr(waitTime), slog.String("error", err.Error()))
+				c.sleep(waitTime)
 			}
 			continue
 		}
 
 		// Success!
 		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			log.Printf("Request successful (status %d)", resp.StatusCode)
-			return body, nil
+			c.logRequest(slog.LevelInfo, fmt.Sprintf("Request successful (status %d)", resp.StatusCode),
+				slog.String("method", method), slog.String("url", reqURL), slog.Int("attempt", attempt),
+				slog.Int("status", resp.StatusCode))
+			c.recordSuccess()
+			if wait := retryAfter(resp.Header); wait > 0 {
+				c.recordRetryAfter(wait)
+			}
+			return body, resp.Header, nil
+		}
+
+		// Conditional request matched the cached version
+		if resp.StatusCode == http.StatusNotModified {
+			c.logRequest(slog.LevelInfo, fmt.Sprintf("Not modified (status %d)", resp.StatusCode),
+				slog.String("method", method), slog.String("url", reqURL), slog.Int("attempt", attempt),
+				slog.Int("status", resp.StatusCode))
+			return nil, resp.Header, ErrNotModified
 		}
 
 		// Handle rate limiting (429)
 		if resp.StatusCode == 429 {
+			c.recordThrottled()
+
 			if attempt >= maxRetries {
-				log.Printf("Rate limit exceeded and max retries (%d) reached. Giving up.", maxRetries)
-				return nil, fmt.Errorf("rate limit max retries exceeded after %d attempts: %s", maxRetries, string(body))
+				c.logRequest(slog.LevelError, fmt.Sprintf("Rate limit exceeded and max retries (%d) reached. Giving up.", maxRetries),
+					slog.String("method", method), slog.String("url", reqURL), slog.Int("attempt", attempt),
+					slog.Int("status", resp.StatusCode))
+				return nil, nil, fmt.Errorf("rate limit max retries exceeded after %d attempts: %s", maxRetries, string(body))
 			}
 
 			// Check for Retry-After header
-			var waitTime time.Duration
-			retryAfter := resp.Header.Get("Retry-After")
-			if retryAfter != "" {
-				if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
-					waitTime = time.Duration(seconds) * time.Second
-				}
+			waitTime := retryAfter(resp.Header)
+			if waitTime > 0 {
+				c.recordRetryAfter(waitTime)
 			}
 			
 			// If no valid Retry-After, use exponential backoff
@@ -123,18 +518,83 @@ func (c *Client) doRequestWithRetry(method, path string, query url.Values, maxRe
 				waitTime = time.Duration(1<<uint(attempt+1)) * time.Second
 			}
 			
-			log.Printf("Rate limited (429). Waiting %v before retry...", waitTime)
-			time.Sleep(waitTime)
+			c.logRequest(slog.LevelWarn, fmt.Sprintf("Rate limited (429). Waiting %v before retry...", waitTime),
+				slog.String("method", method), slog.String("url", reqURL), slog.Int("attempt", attempt),
+				slog.Int("status", resp.StatusCode), waitAttr(waitTime))
+			c.sleep(waitTime)
+			continue
+		}
+
+		// An expired token: refresh it and retry once, without counting
+		// the attempt as a retry
+		if resp.StatusCode == http.StatusUnauthorized && !refreshed && c.refreshToken() {
+			refreshed = true
+			c.logRequest(slog.LevelWarn, "Unauthorized (401). Refreshing token and retrying...",
+				slog.String("method", method), slog.String("url", reqURL), slog.Int("attempt", attempt),
+				slog.Int("status", resp.StatusCode))
+			attempt--
+			continue
+		}
+
+		// Transient server errors (e.g. a gateway in front of JIRA)
+		if c.retryableStatuses[resp.StatusCode] {
+			lastErr = &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+			if attempt < maxRetries {
+				waitTime := time.Duration(1<<uint(attempt+1)) * time.Second
+				c.logRequest(slog.LevelWarn, fmt.Sprintf("Server error (%d). Waiting %v before retry...", resp.StatusCode, waitTime),
+					slog.String("method", method), slog.String("url", reqURL), slog.Int("attempt", attempt),
+					slog.Int("status", resp.StatusCode), waitAttr(waitTime))
+				c.sleep(waitTime)
+			}
 			continue
 		}
 
 		// Other errors (don't retry)
-		log.Printf("API error: status %d", resp.StatusCode)
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		c.logRequest(slog.LevelError, fmt.Sprintf("API error: status %d", resp.StatusCode),
+			slog.String("method", method), slog.String("url", reqURL), slog.Int("attempt", attempt),
+			slog.Int("status", resp.StatusCode))
+		return nil, nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
-	log.Printf("Max retries (%d) exceeded", maxRetries)
-	return nil, fmt.Errorf("max retries exceeded: %w", lastErr)
+	c.logRequest(slog.LevelError, fmt.Sprintf("Max retries (%d) exceeded", maxRetries),
+		slog.String("method", method), slog.String("url", reqURL), slog.Int("attempt", maxRetries))
+	return nil, nil, fmt.Errorf("max retries exceeded: %w", lastErr)
+}
+
+// decodeBody returns a reader of the decompressed response body according
+// to its Content-Encoding
+func decodeBody(resp *http.Response) (io.Reader, error) {
+	// Bodiless responses (e.g. 304) may still carry the header
+	if resp.ContentLength == 0 || resp.StatusCode == http.StatusNotModified || resp.StatusCode == http.StatusNoContent {
+		return resp.Body, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "", "identity":
+		return resp.Body, nil
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress response: %w", err)
+		}
+		return reader, nil
+	case "deflate":
+		reader, err := zlib.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress response: %w", err)
+		}
+		return reader, nil
+	default:
+		return nil, fmt.Errorf("unsupported response encoding %q", resp.Header.Get("Content-Encoding"))
+	}
+}
+
+// retryAfter returns the wait requested by a Retry-After header in
+// seconds, or zero if it is absent or not a positive number
+func retryAfter(header http.Header) time.Duration {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 0
 }
 
 // Search executes a JQL query and returns issue keys
@@ -150,14 +610,50 @@ func (c *Client) Search(jql string, maxResults int, startAt int) (*models.Search
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
 
-	var result models.SearchResult
-	if err := json.Unmarshal(body, &result); err != nil {
+	// Decode issues one at a time so a single malformed record does not
+	// lose the whole page
+	var raw struct {
+		StartAt    int               `json:"startAt"`
+		MaxResults int               `json:"maxResults"`
+		Total      int               `json:"total"`
+		Issues     []json.RawMessage `json:"issues"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
 		return nil, fmt.Errorf("failed to parse search results: %w", err)
 	}
 
+	result := models.SearchResult{
+		StartAt:    raw.StartAt,
+		MaxResults: raw.MaxResults,
+		Total:      raw.Total,
+		Issues:     make([]*models.Issue, 0, len(raw.Issues)),
+	}
+	for i, data := range raw.Issues {
+		var issue *models.Issue
+		var ref struct {
+			Key string `json:"key"`
+		}
+		json.Unmarshal(data, &ref) // Best effort, to name the issue in the warning
+		if err := decodeIssue(ref.Key, data, &issue); err != nil {
+			// Keep a nil placeholder so the page length still drives pagination
+			c.logf(slog.LevelWarn, "Warning: skipping malformed search result %d: %v", startAt+i, err)
+			issue = nil
+		}
+		result.Issues = append(result.Issues, issue)
+	}
+
 	return &result, nil
 }
 
+// Count returns the number of issues matching a JQL query without fetching them
+func (c *Client) Count(jql string) (int, error) {
+	result, err := c.Search(jql, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	return result.Total, nil
+}
+
 // GetIssue fetches a single issue without history
 func (c *Client) GetIssue(key string) (*models.Issue, error) {
 	path := fmt.Sprintf("/rest/api/2/issue/%s", key)
@@ -168,87 +664,638 @@ func (c *Client) GetIssue(key string) (*models.Issue, error) {
 	}
 
 	var issue models.Issue
-	if err := json.Unmarshal(body, &issue); err != nil {
-		return nil, fmt.Errorf("failed to parse issue: %w", err)
+	if err := decodeIssue(key, body, &issue); err != nil {
+		return nil, err
+	}
+
+	if movedKey(key, issue.Key) {
+		c.logf(slog.LevelInfo, "Issue %s has moved to %s", key, issue.Key)
 	}
 
 	return &issue, nil
 }
 
+// GetIssueUpdatedTime fetches only an issue's updated timestamp, a tiny
+// request useful for deciding whether a cached copy is stale
+func (c *Client) GetIssueUpdatedTime(key string) (time.Time, error) {
+	path := fmt.Sprintf("/rest/api/2/issue/%s", key)
+	query := url.Values{}
+	query.Set("fields", "updated")
+
+	body, err := c.doRequest("GET", path, query)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get issue updated time: %w", err)
+	}
+
+	var issue models.Issue
+	if err := json.Unmarshal(body, &issue); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse issue: %w", err)
+	}
+	if issue.Fields == nil || issue.Fields.Updated == "" {
+		return time.Time{}, fmt.Errorf("issue %s has no updated field", key)
+	}
+
+	return models.ParseTime(issue.Fields.Updated)
+}
+
 // GetIssueWithHistory fetches issue with complete changelog
 func (c *Client) GetIssueWithHistory(key string) (*models.IssueWithHistory, time.Duration, error) {
+	result, err := c.FetchIssue(key, FetchOptions{Expand: []string{"changelog"}})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get issue with history: %w", err)
+	}
+	return result.Issue, result.Duration, nil
+}
+
+// FetchOptions controls how FetchIssue requests an issue
+type FetchOptions struct {
+	Fields       []string // Values for the fields parameter; empty requests all fields
+	Expand       []string // Values for the expand parameter, e.g. "changelog"
+	ETag         string   // Sent as If-None-Match when set
+	LastModified string   // Sent as If-Modified-Since when set
+}
+
+// FetchResult is the outcome of FetchIssue
+type FetchResult struct {
+	Issue        *models.IssueWithHistory
+	ETag         string
+	LastModified string
+	Duration     time.Duration
+
+	// RequestedKey is the key that was asked for. It differs from
+	// Issue.Key when the issue has been moved and JIRA resolved the old key.
+	RequestedKey string
+
+	// Raw is the response body exactly as JIRA returned it
+	Raw []byte
+}
+
+// Moved reports whether the fetched issue now lives under a different key
+func (r *FetchResult) Moved() bool {
+	return movedKey(r.RequestedKey, r.Issue.Key)
+}
+
+// movedKey reports whether a requested issue key resolved to a different
+// key. Lookups by numeric ID are never considered moves.
+func movedKey(requested, actual string) bool {
+	if requested == "" || actual == "" || strings.EqualFold(requested, actual) {
+		return false
+	}
+	_, err := strconv.ParseInt(requested, 10, 64)
+	return err != nil
+}
+
+// FetchIssue fetches an issue with the given options. When ETag or
+// LastModified is set and JIRA reports the issue unchanged, the returned
+// error is ErrNotModified.
+func (c *Client) FetchIssue(key string, opts FetchOptions) (*FetchResult, error) {
 	start := time.Now()
-	
+
 	path := fmt.Sprintf("/rest/api/2/issue/%s", key)
 	query := url.Values{}
-	query.Set("expand", "changelog")
-	
-	body, err := c.doRequest("GET", path, query)
+	if len(opts.Fields) > 0 {
+		query.Set("fields", strings.Join(opts.Fields, ","))
+	}
+	if len(opts.Expand) > 0 {
+		query.Set("expand", strings.Join(opts.Expand, ","))
+	}
+
+	header := http.Header{}
+	if opts.ETag != "" {
+		header.Set("If-None-Match", opts.ETag)
+	}
+	if opts.LastModified != "" {
+		header.Set("If-Modified-Since", opts.LastModified)
+	}
+
+	body, respHeader, err := c.doRequestWithHeaders("GET", path, query, header)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to get issue with history: %w", err)
+		return nil, err
 	}
 
 	var issue models.IssueWithHistory
-	if err := json.Unmarshal(body, &issue); err != nil {
-		return nil, 0, fmt.Errorf("failed to parse issue: %w", err)
+	if err := decodeIssue(key, body, &issue); err != nil {
+		return nil, err
+	}
+
+	if movedKey(key, issue.Key) {
+		c.logf(slog.LevelInfo, "Issue %s has moved to %s", key, issue.Key)
 	}
 
-	duration := time.Since(start)
-	return &issue, duration, nil
+	return &FetchResult{
+		Issue:        &issue,
+		ETag:         respHeader.Get("ETag"),
+		LastModified: respHeader.Get("Last-Modified"),
+		Duration:     time.Since(start),
+		RequestedKey: key,
+		Raw:          body,
+	}, nil
+}
+
+// GetChangelog fetches the complete changelog for an issue. It pages through
+// the dedicated changelog endpoint and falls back to expand=changelog on
+// JIRA versions that do not provide it.
+func (c *Client) GetChangelog(key string) (*models.Changelog, error) {
+	path := fmt.Sprintf("/rest/api/2/issue/%s/changelog", key)
+	changelog := &models.Changelog{}
+	startAt := 0
+
+	for {
+		query := url.Values{}
+		query.Set("startAt", fmt.Sprintf("%d", startAt))
+		query.Set("maxResults", "100")
+
+		body, err := c.doRequest("GET", path, query)
+		if err != nil {
+			if isStatus(err, http.StatusNotFound) && startAt == 0 {
+				return c.getChangelogExpanded(key)
+			}
+			return nil, fmt.Errorf("failed to get changelog: %w", err)
+		}
+
+		var page models.ChangelogPage
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse changelog: %w", err)
+		}
+
+		changelog.Histories = append(changelog.Histories, page.Values...)
+		changelog.Total = page.Total
+
+		if page.IsLast || len(page.Values) == 0 || startAt+len(page.Values) >= page.Total {
+			break
+		}
+		startAt += len(page.Values)
+	}
+
+	changelog.MaxResults = len(changelog.Histories)
+	return changelog, nil
+}
+
+// getChangelogExpanded fetches the changelog embedded in the issue response
+func (c *Client) getChangelogExpanded(key string) (*models.Changelog, error) {
+	issue, _, err := c.GetIssueWithHistory(key)
+	if err != nil {
+		return nil, err
+	}
+	if issue.Changelog == nil {
+		return &models.Changelog{}, nil
+	}
+	return issue.Changelog, nil
+}
+
+// GetFieldHistory returns the changes to a single field (e.g. "status"),
+// oldest first
+func (c *Client) GetFieldHistory(key, field string) ([]models.FieldChange, error) {
+	changelog, err := c.GetChangelog(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []models.FieldChange
+	for _, history := range changelog.Histories {
+		for _, item := range history.Items {
+			if !strings.EqualFold(item.Field, field) {
+				continue
+			}
+			changes = append(changes, models.FieldChange{
+				HistoryItem: item,
+				HistoryID:   history.ID,
+				Author:      history.Author,
+				Created:     history.Created,
+			})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		ti, errI := models.ParseTime(changes[i].Created)
+		tj, errJ := models.ParseTime(changes[j].Created)
+		if errI != nil || errJ != nil {
+			return changes[i].Created < changes[j].Created
+		}
+		return ti.Before(tj)
+	})
+
+	return changes, nil
 }
 
 // GetAllIssuesInProject fetches all issue keys for a project
 func (c *Client) GetAllIssuesInProject(project string, orderBy string, limit int) ([]string, error) {
 	if orderBy == "" {
-		orderBy = "updated DESC"
+		orderBy = DefaultOrderBy
 	}
-	
-	jql := fmt.Sprintf("project = %s ORDER BY %s", project, orderBy)
-	
-	var allKeys []string
+	query, err := ProjectJQL(project, orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.GetAllIssuesForJQL(query, limit)
+}
+
+// ProjectJQL builds the discovery query for all issues in a project,
+// returning an error if orderBy is not a valid ORDER BY clause
+func ProjectJQL(project string, orderBy string) (string, error) {
+	return jql.New(jql.Project(project)).OrderByClause(orderBy).Build()
+}
+
+// GetAllIssuesForJQL fetches all issue keys matching a JQL query
+func (c *Client) GetAllIssuesForJQL(jql string, limit int) ([]string, error) {
+	issues, err := c.SearchAll(jql, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		keys = append(keys, issue.Key)
+	}
+	return keys, nil
+}
+
+// SearchAll pages through every issue matching a JQL query, returning the
+// summary fields requested by Search (including updated) in result order.
+// With a discovery checkpoint set, progress is saved periodically and an
+// interrupted search resumes from its last saved page.
+func (c *Client) SearchAll(jql string, limit int) ([]*models.Issue, error) {
+	var allIssues []*models.Issue
 	startAt := 0
 
-	log.Printf("Searching with batch size: %d", c.batchSize)
+	if c.checkpoint != nil {
+		if savedStart, saved, ok := c.checkpoint.LoadDiscovery(jql); ok {
+			c.logf(slog.LevelInfo, "Resuming discovery at result %d (%d issues found so far)", savedStart, len(saved))
+			startAt = savedStart
+			allIssues = saved
+		} else {
+			// Start a new log rather than append to stale progress
+			c.clearDiscovery(jql)
+		}
+	}
+	saved := len(allIssues)
+	seen := make(map[string]bool, len(allIssues))
+	for _, issue := range allIssues {
+		seen[issue.Key] = true
+	}
+
 	if limit > 0 {
-		log.Printf("Limiting search to %d issues", limit)
+		c.logf(slog.LevelInfo, "Limiting search to %d issues", limit)
 	}
 
+	pages := 0
+	err := c.walkSearch(jql, startAt, seen, func(issue *models.Issue) bool {
+		allIssues = append(allIssues, issue)
+
+		// Check if we've hit the limit
+		if limit > 0 && len(allIssues) >= limit {
+			c.logf(slog.LevelInfo, "Reached limit of %d issues, stopping search", limit)
+			return false
+		}
+		return true
+	}, func(next int) {
+		pages++
+		if c.checkpoint != nil && pages%c.checkpointEvery == 0 {
+			if err := c.checkpoint.AppendDiscovery(jql, next, allIssues[saved:]); err != nil {
+				c.logf(slog.LevelWarn, "Warning: failed to save discovery checkpoint: %v", err)
+			} else {
+				saved = len(allIssues)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.clearDiscovery(jql)
+	return allIssues, nil
+}
+
+// StreamIssueKeys sends the key of every issue matching a JQL query to out
+// as each page arrives, instead of collecting them, and closes out when
+// done. Sends block until the consumer receives, so only one page of
+// issues is held at a time, plus the set of keys already sent: keys that
+// shift onto a later page while issues change mid-search are sent once.
+//
+// Cancelling ctx stops the stream at the next send and returns ctx.Err();
+// a page request already in flight completes first.
+func (c *Client) StreamIssueKeys(ctx context.Context, jql string, limit int, out chan<- string) error {
+	defer close(out)
+
+	sent := 0
+	var cancelled error
+	err := c.walkSearch(jql, 0, map[string]bool{}, func(issue *models.Issue) bool {
+		select {
+		case out <- issue.Key:
+		case <-ctx.Done():
+			cancelled = ctx.Err()
+			return false
+		}
+		sent++
+		return limit <= 0 || sent < limit
+	}, nil)
+	if err != nil {
+		return err
+	}
+	return cancelled
+}
+
+// SetSearchProgress sets a callback invoked after each page of a paginated
+// search with the number of results processed so far and the total
+// reported by JIRA. nil disables it.
+func (c *Client) SetSearchProgress(fn func(fetched, total int)) {
+	c.searchProgress = fn
+}
+
+// walkSearch pages through a search from startAt, calling visit for each
+// issue until it returns false. Issues already in seen (when non-nil) are
+// skipped, as results can shift between pages when issues change
+// mid-search. pageDone, if set, is called after each page that is followed
+// by another, with the offset of the next page.
+func (c *Client) walkSearch(jql string, startAt int, seen map[string]bool, visit func(*models.Issue) bool, pageDone func(next int)) error {
+	c.logf(slog.LevelInfo, "Searching with batch size: %d", c.EffectiveBatchSize())
+
 	for {
-		result, err := c.Search(jql, c.batchSize, startAt)
+		result, err := c.Search(jql, c.EffectiveBatchSize(), startAt)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		for _, issue := range result.Issues {
-			allKeys = append(allKeys, issue.Key)
-			
-			// Check if we've hit the limit
-			if limit > 0 && len(allKeys) >= limit {
-				log.Printf("Reached limit of %d issues, stopping search", limit)
-				return allKeys, nil
+		for i, issue := range result.Issues {
+			// Permission-restricted issues can come back null or without a
+			// key; skip them rather than caching under an empty filename
+			if issue == nil || issue.Key == "" {
+				c.logf(slog.LevelWarn, "Warning: skipping search result %d with no issue key (possibly restricted)", startAt+i)
+				continue
+			}
+			if seen != nil {
+				if seen[issue.Key] {
+					continue
+				}
+				seen[issue.Key] = true
 			}
+			if !visit(issue) {
+				return nil
+			}
+		}
+
+		if c.searchProgress != nil {
+			c.searchProgress(startAt+len(result.Issues), result.Total)
 		}
 
 		// Check if we've fetched all issues
-		if startAt+len(result.Issues) >= result.Total {
-			break
+		if startAt+len(result.Issues) >= result.Total || len(result.Issues) == 0 {
+			return nil
 		}
 
 		startAt += len(result.Issues)
-		
-		// Small delay between pagination requests to avoid rate limits
-		time.Sleep(500 * time.Millisecond)
+		if pageDone != nil {
+			pageDone(startAt)
+		}
+
+		// Delay between pagination requests to avoid rate limits, adapted
+		// to the server's throttling signals
+		if delay := c.PageDelay(); delay > 0 {
+			c.sleep(delay)
+		}
 	}
+}
 
-	return allKeys, nil
+// clearDiscovery removes the checkpoint of a finished search
+func (c *Client) clearDiscovery(jql string) {
+	if c.checkpoint == nil {
+		return
+	}
+	if err := c.checkpoint.ClearDiscovery(jql); err != nil {
+		c.logf(slog.LevelWarn, "Warning: failed to clear discovery checkpoint: %v", err)
+	}
+}
+
+// GetProjects lists all projects visible to the authenticated user. It uses
+// the paginated /project/search endpoint where available and falls back to
+// the flat /project endpoint on older JIRA Server/Data Center versions.
+func (c *Client) GetProjects() ([]models.Project, error) {
+	var projects []models.Project
+	startAt := 0
+
+	for {
+		query := url.Values{}
+		query.Set("startAt", fmt.Sprintf("%d", startAt))
+		query.Set("maxResults", "50")
+		query.Set("expand", "lead")
+
+		body, err := c.doRequest("GET", "/rest/api/2/project/search", query)
+		if err != nil {
+			if isStatus(err, http.StatusNotFound) && startAt == 0 {
+				return c.getProjectsLegacy()
+			}
+			return nil, fmt.Errorf("failed to list projects: %w", err)
+		}
+
+		var page models.ProjectPage
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse projects: %w", err)
+		}
+
+		projects = append(projects, page.Values...)
+
+		if page.IsLast || len(page.Values) == 0 {
+			break
+		}
+		startAt += len(page.Values)
+	}
+
+	return projects, nil
+}
+
+// getProjectsLegacy lists projects using the unpaginated endpoint
+func (c *Client) getProjectsLegacy() ([]models.Project, error) {
+	query := url.Values{}
+	query.Set("expand", "lead")
+
+	body, err := c.doRequest("GET", "/rest/api/2/project", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	var projects []models.Project
+	if err := json.Unmarshal(body, &projects); err != nil {
+		return nil, fmt.Errorf("failed to parse projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+// GetFilter resolves a saved filter, including its JQL
+func (c *Client) GetFilter(id string) (*models.Filter, error) {
+	path := fmt.Sprintf("/rest/api/2/filter/%s", url.PathEscape(id))
+
+	body, err := c.doRequest("GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get filter %s: %w", id, err)
+	}
+
+	var filter models.Filter
+	if err := json.Unmarshal(body, &filter); err != nil {
+		return nil, fmt.Errorf("failed to parse filter: %w", err)
+	}
+
+	if filter.JQL == "" {
+		return nil, fmt.Errorf("filter %s has no JQL", id)
+	}
+
+	return &filter, nil
+}
+
+// GetSprintsForBoard returns every sprint of a JIRA Software board, paging
+// through the Agile API
+func (c *Client) GetSprintsForBoard(boardID int) ([]models.Sprint, error) {
+	path := fmt.Sprintf("/rest/agile/1.0/board/%d/sprint", boardID)
+	var sprints []models.Sprint
+	startAt := 0
+
+	for {
+		query := url.Values{}
+		query.Set("startAt", fmt.Sprintf("%d", startAt))
+		query.Set("maxResults", "50")
+
+		body, err := c.doRequest("GET", path, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sprints for board %d: %w", boardID, err)
+		}
+
+		var page models.SprintPage
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse sprints: %w", err)
+		}
+
+		sprints = append(sprints, page.Values...)
+		if page.IsLast || len(page.Values) == 0 {
+			break
+		}
+		startAt += len(page.Values)
+	}
+
+	return sprints, nil
+}
+
+// GetStatuses returns every issue status defined on the instance
+func (c *Client) GetStatuses() ([]models.Status, error) {
+	body, err := c.doRequest("GET", "/rest/api/2/status", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statuses: %w", err)
+	}
+
+	var statuses []models.Status
+	if err := json.Unmarshal(body, &statuses); err != nil {
+		return nil, fmt.Errorf("failed to parse statuses: %w", err)
+	}
+
+	return statuses, nil
+}
+
+// GetProjectStatuses returns the statuses available to each issue type of a
+// project, with their categories
+func (c *Client) GetProjectStatuses(project string) ([]models.IssueTypeStatuses, error) {
+	path := fmt.Sprintf("/rest/api/2/project/%s/statuses", url.PathEscape(project))
+	body, err := c.doRequest("GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statuses for project %s: %w", project, err)
+	}
+
+	var types []models.IssueTypeStatuses
+	if err := json.Unmarshal(body, &types); err != nil {
+		return nil, fmt.Errorf("failed to parse project statuses: %w", err)
+	}
+
+	return types, nil
+}
+
+// GetIssueTypes returns every issue type defined on the instance
+func (c *Client) GetIssueTypes() ([]models.IssueType, error) {
+	body, err := c.doRequest("GET", "/rest/api/2/issuetype", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue types: %w", err)
+	}
+
+	var types []models.IssueType
+	if err := json.Unmarshal(body, &types); err != nil {
+		return nil, fmt.Errorf("failed to parse issue types: %w", err)
+	}
+
+	return types, nil
+}
+
+// GetRemoteLinks returns the links from an issue to external systems
+func (c *Client) GetRemoteLinks(key string) ([]models.RemoteLink, error) {
+	path := fmt.Sprintf("/rest/api/2/issue/%s/remotelink", url.PathEscape(key))
+
+	body, err := c.doRequest("GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote links for %s: %w", key, err)
+	}
+
+	var links []models.RemoteLink
+	if err := json.Unmarshal(body, &links); err != nil {
+		return nil, fmt.Errorf("failed to parse remote links: %w", err)
+	}
+
+	return links, nil
+}
+
+// GetAvailableTransitions returns the workflow transitions currently
+// available on an issue to the authenticated user, with their target
+// statuses
+func (c *Client) GetAvailableTransitions(key string) ([]models.AvailableTransition, error) {
+	path := fmt.Sprintf("/rest/api/2/issue/%s/transitions", url.PathEscape(key))
+
+	body, err := c.doRequest("GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transitions for %s: %w", key, err)
+	}
+
+	var response struct {
+		Transitions []models.AvailableTransition `json:"transitions"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse transitions: %w", err)
+	}
+
+	return response.Transitions, nil
+}
+
+// GetFields returns all system and custom field definitions
+func (c *Client) GetFields() ([]models.FieldMeta, error) {
+	body, err := c.doRequest("GET", "/rest/api/2/field", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fields: %w", err)
+	}
+
+	var fields []models.FieldMeta
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse fields: %w", err)
+	}
+
+	return fields, nil
 }
 
 // TestConnection verifies the JIRA connection and authentication
 func (c *Client) TestConnection() error {
-	_, err := c.doRequest("GET", "/rest/api/2/myself", nil)
+	_, err := c.GetMyself()
 	if err != nil {
 		return fmt.Errorf("connection test failed: %w", err)
 	}
 	return nil
 }
 
+// GetMyself returns the user the client is authenticated as
+func (c *Client) GetMyself() (*models.User, error) {
+	body, err := c.doRequest("GET", "/rest/api/2/myself", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	var user models.User
+	if err := json.Unmarshal(body, &user); err != nil {
+		return nil, fmt.Errorf("failed to parse current user: %w", err)
+	}
+
+	return &user, nil
+}
+
@@ -0,0 +1,528 @@
+package jira
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// fakeResponse is a canned response returned by fakeDoer
+type fakeResponse struct {
+	status int
+	body   string
+	header http.Header
+}
+
+// fakeDoer returns its responses in order, repeating the last one
+type fakeDoer struct {
+	responses []fakeResponse
+	calls     int
+}
+
+func (f *fakeDoer) Do(req *http.Request) (*http.Response, error) {
+	r := f.responses[min(f.calls, len(f.responses)-1)]
+	f.calls++
+	header := r.header
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode:    r.status,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(r.body)),
+		ContentLength: int64(len(r.body)),
+		Request:       req,
+	}, nil
+}
+
+// newTestClient returns a client using doer that records rather than
+// performs its waits
+func newTestClient(doer Doer) (*Client, *[]time.Duration) {
+	c := New("https://jira.example.com", "token")
+	c.SetDoer(doer)
+	var waits []time.Duration
+	c.sleep = func(d time.Duration) { waits = append(waits, d) }
+	return c, &waits
+}
+
+func TestDoRequestWithRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		responses  []fakeResponse
+		maxRetries int
+		wantBody   string
+		wantErr    func(error) bool
+		wantCalls  int
+		wantWaits  int
+	}{
+		{
+			name:       "success",
+			responses:  []fakeResponse{{status: 200, body: `{"ok":true}`}},
+			maxRetries: 3,
+			wantBody:   `{"ok":true}`,
+			wantCalls:  1,
+		},
+		{
+			name:       "retryable server error then success",
+			responses:  []fakeResponse{{status: 503, body: "unavailable"}, {status: 502}, {status: 200, body: "done"}},
+			maxRetries: 3,
+			wantBody:   "done",
+			wantCalls:  3,
+			wantWaits:  2,
+		},
+		{
+			name: "rate limited then success",
+			responses: []fakeResponse{
+				{status: 429, header: http.Header{"Retry-After": []string{"1"}}},
+				{status: 200, body: "done"},
+			},
+			maxRetries: 3,
+			wantBody:   "done",
+			wantCalls:  2,
+			wantWaits:  1,
+		},
+		{
+			name:       "server errors exceed max retries",
+			responses:  []fakeResponse{{status: 500, body: "boom"}},
+			maxRetries: 2,
+			wantErr: func(err error) bool {
+				var apiErr *APIError
+				return errors.As(err, &apiErr) && apiErr.StatusCode == 500 &&
+					strings.Contains(err.Error(), "max retries exceeded")
+			},
+			wantCalls: 3,
+			wantWaits: 2,
+		},
+		{
+			name:       "rate limits exceed max retries",
+			responses:  []fakeResponse{{status: 429}},
+			maxRetries: 2,
+			wantErr: func(err error) bool {
+				return strings.Contains(err.Error(), "rate limit max retries exceeded")
+			},
+			wantCalls: 3,
+			wantWaits: 2,
+		},
+		{
+			name:       "client error is not retried",
+			responses:  []fakeResponse{{status: 404, body: "missing"}},
+			maxRetries: 3,
+			wantErr:    func(err error) bool { return isStatus(err, http.StatusNotFound) },
+			wantCalls:  1,
+		},
+		{
+			name:       "not modified",
+			responses:  []fakeResponse{{status: 304}},
+			maxRetries: 3,
+			wantErr:    func(err error) bool { return errors.Is(err, ErrNotModified) },
+			wantCalls:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doer := &fakeDoer{responses: tt.responses}
+			c, waits := newTestClient(doer)
+
+			body, _, err := c.doRequestWithRetry("GET", "/rest/api/2/test", nil, nil, tt.maxRetries)
+			if tt.wantErr != nil {
+				if err == nil || !tt.wantErr(err) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if string(body) != tt.wantBody {
+					t.Errorf("body = %q, want %q", body, tt.wantBody)
+				}
+			}
+			if doer.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", doer.calls, tt.wantCalls)
+			}
+			if len(*waits) != tt.wantWaits {
+				t.Errorf("waits = %v, want %d of them", *waits, tt.wantWaits)
+			}
+		})
+	}
+}
+
+func TestSearchDecodesPartialNullResults(t *testing.T) {
+	body := `{"startAt":0,"maxResults":4,"total":4,"issues":[
+		{"id":"1","key":"P-1","fields":null},
+		{"id":"2","key":"P-2","fields":{"summary":null,"status":null,"assignee":null,"issuetype":null,"components":null,"resolutiondate":null}},
+		{"id":"3","key":"P-3","fields":{"status":{"id":"1","name":5}}},
+		null
+	]}`
+	c, _ := newTestClient(&fakeDoer{responses: []fakeResponse{{status: 200, body: body}}})
+
+	result, err := c.Search("project = P", 4, 0)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if result.Total != 4 || len(result.Issues) != 4 {
+		t.Fatalf("Total = %d with %d issues, want 4 and 4", result.Total, len(result.Issues))
+	}
+
+	if issue := result.Issues[0]; issue == nil || issue.Key != "P-1" || issue.Fields != nil {
+		t.Errorf("issue 0 = %+v, want P-1 without fields", issue)
+	}
+	issue := result.Issues[1]
+	if issue == nil || issue.Key != "P-2" || issue.Fields == nil {
+		t.Fatalf("issue 1 = %+v, want P-2 with fields", issue)
+	}
+	if f := issue.Fields; f.Summary != "" || f.Status != nil || f.Assignee != nil || f.IssueType != nil || f.ResolutionDate != nil {
+		t.Errorf("issue 1 fields = %+v, want null values decoded as zero values", f)
+	}
+	// A malformed issue and a null entry keep their places as nil
+	if result.Issues[2] != nil || result.Issues[3] != nil {
+		t.Errorf("issues 2 and 3 = %+v, %+v, want nil placeholders", result.Issues[2], result.Issues[3])
+	}
+}
+
+// recordingCheckpoint is a DiscoveryCheckpoint recording its saves
+type recordingCheckpoint struct {
+	startAt int
+	saved   []*models.Issue
+	appends [][]string // Keys passed to each AppendDiscovery
+	cleared int
+}
+
+func (r *recordingCheckpoint) LoadDiscovery(jql string) (int, []*models.Issue, bool) {
+	return r.startAt, r.saved, r.saved != nil
+}
+
+func (r *recordingCheckpoint) AppendDiscovery(jql string, startAt int, issues []*models.Issue) error {
+	var keys []string
+	for _, issue := range issues {
+		keys = append(keys, issue.Key)
+	}
+	r.appends = append(r.appends, keys)
+	return nil
+}
+
+func (r *recordingCheckpoint) ClearDiscovery(jql string) error {
+	r.cleared++
+	return nil
+}
+
+// searchPage returns a search response holding the given keys
+func searchPage(startAt, total int, keys ...string) fakeResponse {
+	var issues []string
+	for _, key := range keys {
+		issues = append(issues, fmt.Sprintf(`{"id":"%s","key":"%s","fields":{}}`, key, key))
+	}
+	return fakeResponse{status: 200, body: fmt.Sprintf(`{"startAt":%d,"maxResults":2,"total":%d,"issues":[%s]}`,
+		startAt, total, strings.Join(issues, ","))}
+}
+
+func TestSearchAllAppendsCheckpointDeltas(t *testing.T) {
+	c, _ := newTestClient(&fakeDoer{responses: []fakeResponse{
+		searchPage(0, 6, "P-1", "P-2"),
+		searchPage(2, 6, "P-3", "P-4"),
+		searchPage(4, 6, "P-5", "P-6"),
+	}})
+	c.SetBatchSize(2)
+	checkpoint := &recordingCheckpoint{}
+	c.SetDiscoveryCheckpoint(checkpoint, 1)
+
+	issues, err := c.SearchAll("project = P", 0)
+	if err != nil {
+		t.Fatalf("SearchAll: %v", err)
+	}
+	if len(issues) != 6 {
+		t.Fatalf("found %d issues, want 6", len(issues))
+	}
+	want := [][]string{{"P-1", "P-2"}, {"P-3", "P-4"}}
+	if !reflect.DeepEqual(checkpoint.appends, want) {
+		t.Errorf("appends = %v, want only each page's new issues %v", checkpoint.appends, want)
+	}
+	// Cleared before starting afresh and when done
+	if checkpoint.cleared != 2 {
+		t.Errorf("cleared %d times, want 2", checkpoint.cleared)
+	}
+}
+
+func TestSearchAllResumeAppendsOnlyNewIssues(t *testing.T) {
+	c, _ := newTestClient(&fakeDoer{responses: []fakeResponse{
+		searchPage(2, 6, "P-3", "P-4"),
+		searchPage(4, 6, "P-5", "P-6"),
+	}})
+	c.SetBatchSize(2)
+	checkpoint := &recordingCheckpoint{
+		startAt: 2,
+		saved:   []*models.Issue{{Key: "P-1"}, {Key: "P-2"}},
+	}
+	c.SetDiscoveryCheckpoint(checkpoint, 1)
+
+	issues, err := c.SearchAll("project = P", 0)
+	if err != nil {
+		t.Fatalf("SearchAll: %v", err)
+	}
+	if len(issues) != 6 {
+		t.Fatalf("found %d issues, want 6", len(issues))
+	}
+	if want := [][]string{{"P-3", "P-4"}}; !reflect.DeepEqual(checkpoint.appends, want) {
+		t.Errorf("appends = %v, want %v", checkpoint.appends, want)
+	}
+}
+
+// encodingDoer returns body compressed with encoding, recording the
+// Accept-Encoding header it was sent and the compressed size
+type encodingDoer struct {
+	encoding string
+	body     []byte
+	accepted string
+	sent     int
+}
+
+func (e *encodingDoer) Do(req *http.Request) (*http.Response, error) {
+	e.accepted = req.Header.Get("Accept-Encoding")
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch e.encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	}
+	w.Write(e.body)
+	w.Close()
+	e.sent = buf.Len()
+	return &http.Response{
+		StatusCode:    200,
+		Header:        http.Header{"Content-Encoding": []string{e.encoding}},
+		Body:          io.NopCloser(&buf),
+		ContentLength: int64(buf.Len()),
+		Request:       req,
+	}, nil
+}
+
+func TestCompressedResponsesAreDecoded(t *testing.T) {
+	// A generated page of 100 changelog entries with full author objects
+	body, err := os.ReadFile("testdata/changelog_page.json")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	for _, encoding := range []string{"gzip", "deflate"} {
+		t.Run(encoding, func(t *testing.T) {
+			doer := &encodingDoer{encoding: encoding, body: body}
+			c, _ := newTestClient(doer)
+
+			got, _, err := c.doRequestWithRetry("GET", "/rest/api/2/issue/P-1/changelog", nil, nil, 0)
+			if err != nil {
+				t.Fatalf("doRequestWithRetry: %v", err)
+			}
+			if !bytes.Equal(got, body) {
+				t.Errorf("decoded body differs from the original (%d bytes, want %d)", len(got), len(body))
+			}
+			if doer.accepted != "gzip, deflate" {
+				t.Errorf("Accept-Encoding = %q, want %q", doer.accepted, "gzip, deflate")
+			}
+			t.Logf("%s: %d bytes on the wire for a %d byte page", encoding, doer.sent, len(body))
+		})
+	}
+}
+
+// contextDoer fails requests whose context has already expired, as a real
+// transport would
+type contextDoer struct{}
+
+func (contextDoer) Do(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("ok")),
+		Request:    req,
+	}, nil
+}
+
+func TestSlotWaitDoesNotCountAgainstTimeout(t *testing.T) {
+	c, _ := newTestClient(contextDoer{})
+	c.SetMaxConcurrency(1)
+	c.SetAPITimeout(20 * time.Millisecond)
+
+	// Saturate the semaphore for longer than the API timeout
+	c.acquireSlot()
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := c.doRequestWithRetry("GET", "/rest/api/2/test", nil, nil, 0)
+		done <- err
+	}()
+	time.Sleep(60 * time.Millisecond)
+	c.releaseSlot()
+
+	if err := <-done; err != nil {
+		t.Fatalf("request queued behind a busy slot failed: %v", err)
+	}
+	if err := c.breaker.allow(); err != nil {
+		t.Errorf("breaker = %v after a successful request", err)
+	}
+}
+
+func TestProjectJQLRejectsInvalidOrderBy(t *testing.T) {
+	query, err := ProjectJQL("PROJ", "key; DROP")
+	if err == nil || query != "" {
+		t.Errorf("ProjectJQL = %q, %v, want an error rather than an unfiltered query", query, err)
+	}
+
+	doer := &fakeDoer{responses: []fakeResponse{{status: 200, body: `{"issues":[]}`}}}
+	client, _ := newTestClient(doer)
+	if _, err := client.GetAllIssuesInProject("PROJ", "key; DROP", 0); err == nil || doer.calls != 0 {
+		t.Errorf("GetAllIssuesInProject = %v after %d requests, want an error before searching", err, doer.calls)
+	}
+
+	query, err = ProjectJQL(`A"B`, DefaultOrderBy)
+	if err != nil || query != `project = "A\"B" ORDER BY `+DefaultOrderBy {
+		t.Errorf("ProjectJQL = %q, %v", query, err)
+	}
+}
+
+func TestGetStatusesDecodesCategories(t *testing.T) {
+	doer := &fakeDoer{responses: []fakeResponse{
+		{status: 200, body: `[{"id":"1","name":"Open","statusCategory":{"id":2,"key":"new","name":"To Do"}},{"id":"6","name":"Closed","statusCategory":{"id":3,"key":"done","name":"Done"}}]`},
+		{status: 200, body: `[{"id":"1","name":"Bug","statuses":[{"id":"6","name":"Closed","statusCategory":{"id":3,"key":"done","name":"Done"}}]}]`},
+	}}
+	c, _ := newTestClient(doer)
+
+	statuses, err := c.GetStatuses()
+	if err != nil {
+		t.Fatalf("GetStatuses: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].IsDone() || !statuses[1].IsDone() {
+		t.Errorf("GetStatuses = %+v, want Open not done and Closed done", statuses)
+	}
+
+	types, err := c.GetProjectStatuses("PROJ")
+	if err != nil {
+		t.Fatalf("GetProjectStatuses: %v", err)
+	}
+	if categories := models.StatusCategoryKeys(types); categories["Closed"] != models.StatusCategoryDone {
+		t.Errorf("StatusCategoryKeys = %v, want Closed done", categories)
+	}
+}
+
+func TestJSONLoggerCoversClientEvents(t *testing.T) {
+	var logs bytes.Buffer
+	c, _ := newTestClient(&fakeDoer{responses: []fakeResponse{{status: 503}}})
+	c.SetLogger(NewJSONLogger(&logs))
+	c.SetCircuitBreaker(1, time.Minute)
+
+	if _, _, err := c.doRequestWithRetry("GET", "/rest/api/2/test", nil, nil, 0); err == nil {
+		t.Fatal("request succeeded, want the 503 to fail it")
+	}
+
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", line, err)
+		}
+		events = append(events, event)
+	}
+
+	var request, breaker map[string]any
+	for _, event := range events {
+		msg, _ := event["msg"].(string)
+		switch {
+		case strings.HasPrefix(msg, "Request: "):
+			request = event
+		case strings.HasPrefix(msg, "Circuit breaker open"):
+			breaker = event
+		}
+	}
+	if request == nil || request["method"] != "GET" || request["level"] != "INFO" {
+		t.Errorf("request event = %v, want GET at INFO", request)
+	}
+	if breaker == nil || breaker["level"] != "WARN" {
+		t.Errorf("breaker event = %v, want it at WARN; got %v", breaker, events)
+	}
+}
+
+func TestStreamIssueKeys(t *testing.T) {
+	pages := []fakeResponse{
+		{status: 200, body: `{"startAt":0,"total":3,"issues":[{"key":"P-1"},{"key":"P-2"}]}`},
+		// P-2 shifted onto the second page while the search ran
+		{status: 200, body: `{"startAt":2,"total":3,"issues":[{"key":"P-2"},{"key":"P-3"}]}`},
+	}
+
+	c, _ := newTestClient(&fakeDoer{responses: pages})
+	out := make(chan string)
+	errc := make(chan error, 1)
+	go func() { errc <- c.StreamIssueKeys(context.Background(), "project = P", 0, out) }()
+	var keys []string
+	for key := range out {
+		keys = append(keys, key)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("StreamIssueKeys: %v", err)
+	}
+	if want := []string{"P-1", "P-2", "P-3"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+
+	c, _ = newTestClient(&fakeDoer{responses: pages})
+	ctx, cancel := context.WithCancel(context.Background())
+	out = make(chan string)
+	go func() { errc <- c.StreamIssueKeys(ctx, "project = P", 0, out) }()
+	<-out
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("StreamIssueKeys after cancel = %v, want context.Canceled", err)
+	}
+	if _, open := <-out; open {
+		t.Error("out not closed after cancellation")
+	}
+}
+
+func TestDownloadResumeChecksContentRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		response fakeResponse
+		want     string
+		wantErr  bool
+	}{
+		{name: "requested range", response: fakeResponse{status: 206, body: "world", header: http.Header{"Content-Range": {"bytes 5-9/10"}}}, want: "helloworld"},
+		{name: "unknown total", response: fakeResponse{status: 206, body: "world", header: http.Header{"Content-Range": {"bytes 5-9/*"}}}, want: "helloworld"},
+		{name: "range ignored", response: fakeResponse{status: 200, body: "HELLOWORLD"}, want: "HELLOWORLD"},
+		{name: "wrong start", response: fakeResponse{status: 206, body: "helloworld", header: http.Header{"Content-Range": {"bytes 0-9/10"}}}, want: "hello", wantErr: true},
+		{name: "wrong total", response: fakeResponse{status: 206, body: "world", header: http.Header{"Content-Range": {"bytes 5-9/12"}}}, want: "hello", wantErr: true},
+		{name: "missing header", response: fakeResponse{status: 206, body: "world"}, want: "hello", wantErr: true},
+		{name: "malformed header", response: fakeResponse{status: 206, body: "world", header: http.Header{"Content-Range": {"bytes 5-x/10"}}}, want: "hello", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := t.TempDir() + "/file.bin"
+			if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			c, _ := newTestClient(&fakeDoer{responses: []fakeResponse{tt.response}})
+
+			err := c.downloadToFile("https://jira.example.com/secure/attachment/1/file.bin", path, 10)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("downloadToFile error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got, _ := os.ReadFile(path); string(got) != tt.want {
+				t.Errorf("file = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
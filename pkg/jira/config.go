@@ -0,0 +1,170 @@
+package jira
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ClientConfig holds every client option in one place for NewWithConfig.
+// Zero values select the defaults of New; for the options noted, a
+// negative value disables the feature instead.
+type ClientConfig struct {
+	BaseURL string // Required
+	Token   string // Bearer token, or the API token when Email is set (required without TokenProvider)
+	Email   string // Account email for JIRA Cloud basic auth
+
+	TokenProvider TokenProvider // Supplies an expiring token per request, in place of Token
+
+	BatchSize         int           // Search batch size, 1-100 (default 10)
+	RequestDelay      time.Duration // Delay between sequential requests (default 500ms; negative: none)
+	MaxRetries        int           // Retries per request (default 3; negative: none)
+	RetryableStatuses []int         // Statuses retried with backoff (default 500, 502, 503, 504)
+	MaxConcurrency    int           // Cap on in-flight requests (default: unlimited)
+	MaxPageDelay      time.Duration // Ceiling of the adaptive delay between search pages (default 30s; negative: fixed)
+
+	APITimeout      time.Duration // Per-request API deadline (default 30s; negative: none)
+	DownloadTimeout time.Duration // Per-download deadline (default: none)
+	MaxResponseSize int64         // Largest API response body (default 64MB; negative: unlimited)
+
+	BreakerThreshold int           // Consecutive failures that open the circuit breaker (default 10; negative: disabled)
+	BreakerCooldown  time.Duration // Time the breaker stays open (default 1m)
+
+	ProxyURL           string      // Proxy for all requests (default: HTTPS_PROXY etc.)
+	CABundle           string      // PEM file of extra CA certificates to trust
+	InsecureSkipVerify bool        // Disable TLS verification (testing only)
+	TLSConfig          *tls.Config // Full TLS configuration; excludes CABundle and InsecureSkipVerify
+
+	Doer Doer // Replaces the HTTP client; excludes the transport options above
+
+	Logger *slog.Logger // Structured logging for the client and its scrapers (default: plain text via the log package)
+}
+
+// validate checks the config for missing values and conflicting options
+func (cfg ClientConfig) validate() error {
+	if cfg.BaseURL == "" {
+		return fmt.Errorf("base URL is required")
+	}
+	u, err := url.Parse(cfg.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("base URL must be an absolute http(s) URL, got %q", cfg.BaseURL)
+	}
+	if cfg.Token == "" && cfg.TokenProvider == nil {
+		return fmt.Errorf("token or token provider is required")
+	}
+	if cfg.BatchSize < 0 || cfg.BatchSize > 100 {
+		return fmt.Errorf("batch size must be from 1 to 100, got %d", cfg.BatchSize)
+	}
+	if cfg.ProxyURL != "" {
+		proxy, err := url.Parse(cfg.ProxyURL)
+		if err != nil || proxy.Host == "" {
+			return fmt.Errorf("proxy URL is not valid: %q", cfg.ProxyURL)
+		}
+	}
+	if cfg.TLSConfig != nil && (cfg.CABundle != "" || cfg.InsecureSkipVerify) {
+		return fmt.Errorf("TLSConfig cannot be combined with CABundle or InsecureSkipVerify")
+	}
+	if cfg.Doer != nil && (cfg.ProxyURL != "" || cfg.CABundle != "" || cfg.InsecureSkipVerify || cfg.TLSConfig != nil) {
+		return fmt.Errorf("transport options have no effect with a custom Doer")
+	}
+	return nil
+}
+
+// NewWithConfig creates a client from a validated configuration
+func NewWithConfig(cfg ClientConfig) (*Client, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid client config: %w", err)
+	}
+
+	c := New(cfg.BaseURL, cfg.Token)
+	if cfg.Logger != nil {
+		// First, so warnings while applying the rest are structured too
+		c.SetLogger(cfg.Logger)
+	}
+	if cfg.Email != "" {
+		c.SetBasicAuth(cfg.Email, cfg.Token)
+	}
+	if cfg.TokenProvider != nil {
+		c.SetTokenProvider(cfg.TokenProvider)
+	}
+
+	if cfg.BatchSize > 0 {
+		c.SetBatchSize(cfg.BatchSize)
+	}
+	switch {
+	case cfg.RequestDelay < 0:
+		c.SetRequestDelay(0)
+	case cfg.RequestDelay > 0:
+		c.SetRequestDelay(cfg.RequestDelay)
+	}
+	switch {
+	case cfg.MaxRetries < 0:
+		c.SetMaxRetries(0)
+	case cfg.MaxRetries > 0:
+		c.SetMaxRetries(cfg.MaxRetries)
+	}
+	if cfg.RetryableStatuses != nil {
+		c.SetRetryableStatuses(cfg.RetryableStatuses)
+	}
+	c.SetMaxConcurrency(cfg.MaxConcurrency)
+	switch {
+	case cfg.MaxPageDelay < 0:
+		c.SetMaxPageDelay(0)
+	case cfg.MaxPageDelay > 0:
+		c.SetMaxPageDelay(cfg.MaxPageDelay)
+	}
+
+	switch {
+	case cfg.APITimeout < 0:
+		c.SetAPITimeout(0)
+	case cfg.APITimeout > 0:
+		c.SetAPITimeout(cfg.APITimeout)
+	}
+	if cfg.DownloadTimeout > 0 {
+		c.SetDownloadTimeout(cfg.DownloadTimeout)
+	}
+	switch {
+	case cfg.MaxResponseSize < 0:
+		c.SetMaxResponseSize(0)
+	case cfg.MaxResponseSize > 0:
+		c.SetMaxResponseSize(cfg.MaxResponseSize)
+	}
+
+	if cfg.BreakerThreshold != 0 || cfg.BreakerCooldown != 0 {
+		threshold, cooldown := cfg.BreakerThreshold, cfg.BreakerCooldown
+		if threshold == 0 {
+			threshold = 10
+		}
+		if threshold < 0 {
+			threshold = 0
+		}
+		if cooldown <= 0 {
+			cooldown = time.Minute
+		}
+		c.SetCircuitBreaker(threshold, cooldown)
+	}
+
+	if cfg.ProxyURL != "" {
+		proxy, _ := url.Parse(cfg.ProxyURL)
+		c.transport().Proxy = http.ProxyURL(proxy)
+	}
+	if cfg.TLSConfig != nil {
+		c.SetTLSConfig(cfg.TLSConfig)
+	}
+	if cfg.CABundle != "" {
+		if err := c.LoadCABundle(cfg.CABundle); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.InsecureSkipVerify {
+		c.SetInsecureSkipVerify(true)
+	}
+	if cfg.Doer != nil {
+		c.SetDoer(cfg.Doer)
+	}
+
+	return c, nil
+}
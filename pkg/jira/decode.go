@@ -0,0 +1,183 @@
+package jira
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DecodeError is a failure to parse an issue, located to the JSON value
+// that did not match the model, so new field shapes are easy to identify
+type DecodeError struct {
+	Key    string // Issue key, when known
+	Path   string // Path of the offending value, e.g. "fields.status.name"; "" if unknown
+	Offset int64  // Byte offset of a syntax error in the response; 0 otherwise
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	var b strings.Builder
+	b.WriteString("failed to parse issue")
+	if e.Key != "" {
+		b.WriteString(" " + e.Key)
+	}
+	if e.Path != "" {
+		b.WriteString(" at " + e.Path)
+	}
+	if e.Offset > 0 {
+		fmt.Fprintf(&b, " (offset %d)", e.Offset)
+	}
+	fmt.Fprintf(&b, ": %v", e.Err)
+	return b.String()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// decodeIssue unmarshals an issue response, returning a *DecodeError
+// naming the issue and the path of the offending value on failure
+func decodeIssue(key string, data []byte, v any) error {
+	err := json.Unmarshal(data, v)
+	if err == nil {
+		return nil
+	}
+
+	decodeErr := &DecodeError{Key: key, Err: err}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		decodeErr.Offset = syntaxErr.Offset
+		decodeErr.Path = jsonPathAt(data, syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		// Field is the Go decoder's path, with indices as ".1" and
+		// relative to the innermost custom UnmarshalJSON (e.g. without
+		// the "fields." of IssueFields, whose offsets are likewise
+		// relative), so look it up in the document
+		decodeErr.Path = locateField(data, typeErr.Field)
+	}
+	return decodeErr
+}
+
+// pathFrame is an open object or array while walking a JSON document
+type pathFrame struct {
+	array bool
+	key   string // Current member name of an object
+	index int    // Index of the current element of an array
+}
+
+// walkJSON calls visit with the enclosing containers of each value in
+// data, in document order, until visit returns false or parsing fails.
+// offset is the input offset just past the value's first token.
+func walkJSON(data []byte, visit func(stack []pathFrame, offset int64) bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var stack []pathFrame
+	expectKey := false
+
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			// Syntax errors stop the walk at the last value read
+			return
+		}
+
+		if expectKey {
+			// Object member names alternate with their values
+			if name, ok := token.(string); ok {
+				stack[len(stack)-1].key = name
+				expectKey = false
+				continue
+			}
+		}
+
+		switch token {
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			advance(stack, &expectKey)
+			continue
+		}
+
+		if !visit(stack, dec.InputOffset()) {
+			return
+		}
+		switch token {
+		case json.Delim('{'):
+			stack = append(stack, pathFrame{})
+			expectKey = true
+		case json.Delim('['):
+			stack = append(stack, pathFrame{array: true})
+		default:
+			advance(stack, &expectKey)
+		}
+	}
+}
+
+// jsonPathAt returns the path of the last value that starts before offset
+// in data, e.g. "fields.components[2].name"
+func jsonPathAt(data []byte, offset int64) string {
+	path := ""
+	walkJSON(data, func(stack []pathFrame, end int64) bool {
+		path = formatPath(stack)
+		return end < offset
+	})
+	return path
+}
+
+// locateField returns the full path of the first value whose Go decoder
+// path (see json.UnmarshalTypeError.Field) is field or ends with it,
+// falling back to field itself
+func locateField(data []byte, field string) string {
+	if field == "" {
+		return ""
+	}
+	found := ""
+	walkJSON(data, func(stack []pathFrame, _ int64) bool {
+		path := formatPath(stack)
+		goPath := pathIndex.ReplaceAllString(path, ".$1")
+		if goPath == field || strings.HasSuffix(goPath, "."+field) {
+			found = path
+			return false
+		}
+		return true
+	})
+	if found == "" {
+		return field
+	}
+	return found
+}
+
+// advance moves past a completed value in the innermost container
+func advance(stack []pathFrame, expectKey *bool) {
+	if len(stack) == 0 {
+		return
+	}
+	top := &stack[len(stack)-1]
+	if top.array {
+		top.index++
+	} else {
+		*expectKey = true
+	}
+}
+
+// formatPath renders a stack of containers as a dotted path with indices
+func formatPath(stack []pathFrame) string {
+	var b strings.Builder
+	for _, frame := range stack {
+		if frame.array {
+			fmt.Fprintf(&b, "[%d]", frame.index)
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(frame.key)
+	}
+	return b.String()
+}
+
+// pathIndex matches an array index of a path
+var pathIndex = regexp.MustCompile(`\[(\d+)\]`)
@@ -0,0 +1,69 @@
+package jira
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+func TestDecodeIssueLocatesErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantPath   string
+		wantOffset bool
+	}{
+		{name: "nested object", body: `{"key":"PROJ-1","fields":{"status":{"name":5}}}`, wantPath: "fields.status.name"},
+		{name: "array element", body: `{"key":"PROJ-1","fields":{"components":[{"name":"a"},{"name":5}]}}`, wantPath: "fields.components[1].name"},
+		{name: "array for an object", body: `{"key":"PROJ-1","fields":{"status":[]}}`, wantPath: "fields.status"},
+		{name: "top level field", body: `{"key":5}`, wantPath: "key"},
+		{name: "after a custom field", body: `{"key":"PROJ-1","fields":{"customfield_10020":{"x":1},"summary":7}}`, wantPath: "fields.summary"},
+		{name: "rich text value", body: `{"key":"PROJ-1","fields":{"description":5}}`, wantPath: "fields.description"},
+		{name: "inside an ADF document", body: `{"key":"PROJ-1","fields":{"description":{"type":"doc","content":5}}}`, wantPath: "fields.description.content"},
+		{name: "ADF comment body", body: `{"key":"PROJ-1","fields":{"comment":{"comments":[{"body":{"type":5}}]}}}`, wantPath: "fields.comment.comments[0].body.type"},
+		{name: "changelog item", body: `{"key":"PROJ-1","changelog":{"histories":[{"id":"1","items":[{"field":"x"},{"field":3}]}]}}`, wantPath: "changelog.histories[0].items[1].field"},
+		{name: "truncated", body: `{"key":"PROJ-1","fields":{"summary":"x","labels":["a",`, wantPath: "fields.labels[0]", wantOffset: true},
+		{name: "syntax error", body: `{"key":"PROJ-1","fields":{"status":{"name":"a"}} x`, wantPath: "fields.status.name", wantOffset: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var issue models.IssueWithHistory
+			err := decodeIssue("PROJ-1", []byte(tt.body), &issue)
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("error = %v, want a *DecodeError", err)
+			}
+			if decodeErr.Key != "PROJ-1" || decodeErr.Path != tt.wantPath {
+				t.Errorf("key %q, path %q, want PROJ-1, %q", decodeErr.Key, decodeErr.Path, tt.wantPath)
+			}
+			if (decodeErr.Offset > 0) != tt.wantOffset {
+				t.Errorf("offset = %d, want one: %v", decodeErr.Offset, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestDecodeErrorMessage(t *testing.T) {
+	var issue models.IssueWithHistory
+	err := decodeIssue("PROJ-1", []byte(`{"key":"PROJ-1","fields":{"status":{"name":5}}}`), &issue)
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Errorf("error %v does not unwrap to the type error", err)
+	}
+	const prefix = "failed to parse issue PROJ-1 at fields.status.name: "
+	if got := err.Error(); len(got) < len(prefix) || got[:len(prefix)] != prefix {
+		t.Errorf("message = %q, want prefix %q", got, prefix)
+	}
+
+	err = decodeIssue("", []byte(`{"key":`), &issue)
+	if got, want := err.Error(), "failed to parse issue (offset 7): unexpected end of JSON input"; got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+
+	if err := decodeIssue("PROJ-1", []byte(`{"key":"PROJ-1","fields":{"summary":"ok"}}`), &issue); err != nil {
+		t.Errorf("valid issue: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package jira
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// NewFromEnv creates a client configured from environment variables, for
+// headless use such as CI jobs and cron:
+//
+//	JIRA_URL                   base URL (required)
+//	JIRA_TOKEN                 personal access token (bearer auth), or
+//	JIRA_EMAIL, JIRA_API_TOKEN JIRA Cloud email and API token (basic auth)
+//	JIRA_BATCH_SIZE            search batch size, 1-100
+//	JIRA_PROXY                 proxy URL; otherwise HTTPS_PROXY etc. apply
+//	JIRA_CA_BUNDLE             PEM file of extra CA certificates to trust
+//	JIRA_INSECURE_SKIP_VERIFY  "true" disables TLS verification (testing only)
+//	JIRA_LOG_FORMAT            "json" logs as JSON to stderr (default "text")
+func NewFromEnv() (*Client, error) {
+	cfg := ClientConfig{
+		BaseURL:  os.Getenv("JIRA_URL"),
+		ProxyURL: os.Getenv("JIRA_PROXY"),
+		CABundle: os.Getenv("JIRA_CA_BUNDLE"),
+	}
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("JIRA_URL is not set")
+	}
+
+	token := os.Getenv("JIRA_TOKEN")
+	email := os.Getenv("JIRA_EMAIL")
+	apiToken := os.Getenv("JIRA_API_TOKEN")
+	switch {
+	case token != "":
+		cfg.Token = token
+	case email != "" && apiToken != "":
+		cfg.Email = email
+		cfg.Token = apiToken
+	case email != "" || apiToken != "":
+		return nil, fmt.Errorf("JIRA_EMAIL and JIRA_API_TOKEN must be set together")
+	default:
+		return nil, fmt.Errorf("JIRA_TOKEN (or JIRA_EMAIL and JIRA_API_TOKEN) is not set")
+	}
+
+	if value := os.Getenv("JIRA_BATCH_SIZE"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 || size > 100 {
+			return nil, fmt.Errorf("JIRA_BATCH_SIZE must be a number from 1 to 100, got %q", value)
+		}
+		cfg.BatchSize = size
+	}
+
+	if value := os.Getenv("JIRA_INSECURE_SKIP_VERIFY"); value != "" {
+		skip, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("JIRA_INSECURE_SKIP_VERIFY must be true or false, got %q", value)
+		}
+		cfg.InsecureSkipVerify = skip
+	}
+
+	switch format := os.Getenv("JIRA_LOG_FORMAT"); format {
+	case "", "text":
+	case "json":
+		cfg.Logger = NewJSONLogger(os.Stderr)
+	default:
+		return nil, fmt.Errorf("JIRA_LOG_FORMAT must be text or json, got %q", format)
+	}
+
+	return NewWithConfig(cfg)
+}
@@ -0,0 +1,50 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"time"
+)
+
+// SetLogger routes the client's logs through logger, along with those of
+// scrapers using the client. Events of the request loop carry the request
+// details (method, url, status, attempt, wait_ms) as attributes. A nil
+// logger restores the default plain-text output via the log package.
+func (c *Client) SetLogger(logger *slog.Logger) {
+	c.logger = logger
+}
+
+// Logger returns the logger set with SetLogger, or nil for plain text
+func (c *Client) Logger() *slog.Logger {
+	return c.logger
+}
+
+// NewJSONLogger returns a logger that writes one JSON object per line to w,
+// for ingestion by log pipelines
+func NewJSONLogger(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, nil))
+}
+
+// logRequest logs an event of the request loop. Without a logger only msg
+// is printed, as plain text.
+func (c *Client) logRequest(level slog.Level, msg string, attrs ...slog.Attr) {
+	if c.logger == nil {
+		log.Print(msg)
+		return
+	}
+	c.logger.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+// logf logs a formatted event outside the request loop, such as
+// throttling or discovery progress
+func (c *Client) logf(level slog.Level, format string, args ...any) {
+	c.logRequest(level, fmt.Sprintf(format, args...))
+}
+
+// waitAttr records a backoff delay in milliseconds
+func waitAttr(wait time.Duration) slog.Attr {
+	return slog.Int64("wait_ms", wait.Milliseconds())
+}
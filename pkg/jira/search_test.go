@@ -0,0 +1,28 @@
+package jira
+
+import (
+	"reflect"
+	"testing"
+)
+
+// partialNullPage is a search page with restricted issues: a null entry, an
+// issue without a key, and issues with null fields or null nested objects
+const partialNullPage = `{"startAt":0,"maxResults":5,"total":5,"issues":[
+	{"id":"1","key":"P-1","fields":null},
+	null,
+	{"id":"3","key":"","fields":{"summary":"hidden"}},
+	{"id":"4","key":"P-4","fields":{"summary":null,"status":null,"assignee":null,"issuetype":null,"components":null,"resolutiondate":null}},
+	{"id":"5","key":"P-5","fields":{"summary":"visible"}}
+]}`
+
+func TestGetAllIssuesForJQLSkipsResultsWithoutKeys(t *testing.T) {
+	c, _ := newTestClient(&fakeDoer{responses: []fakeResponse{{status: 200, body: partialNullPage}}})
+
+	keys, err := c.GetAllIssuesForJQL("project = P", 0)
+	if err != nil {
+		t.Fatalf("GetAllIssuesForJQL: %v", err)
+	}
+	if want := []string{"P-1", "P-4", "P-5"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+}
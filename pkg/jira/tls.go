@@ -0,0 +1,72 @@
+package jira
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+)
+
+// transport returns the client's HTTP transport, replacing the shared
+// default transport with a private clone on first use so settings here
+// never leak into other HTTP clients in the process
+func (c *Client) transport() *http.Transport {
+	if t, ok := c.httpClient.Transport.(*http.Transport); ok {
+		return t
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	c.httpClient.Transport = t
+	return t
+}
+
+// tlsConfig returns the transport's TLS config, creating one if needed
+func (c *Client) tlsConfig() *tls.Config {
+	t := c.transport()
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return t.TLSClientConfig
+}
+
+// SetTLSConfig sets the TLS configuration used to connect to JIRA, e.g. to
+// trust an internal CA or present a client certificate
+func (c *Client) SetTLSConfig(config *tls.Config) {
+	c.transport().TLSClientConfig = config
+}
+
+// SetInsecureSkipVerify disables TLS certificate verification. This makes
+// the connection vulnerable to interception and is only meant for testing
+// against servers with self-signed certificates.
+func (c *Client) SetInsecureSkipVerify(skip bool) {
+	if skip {
+		c.logf(slog.LevelWarn, "WARNING: TLS certificate verification is DISABLED for %s. "+
+			"Connections can be intercepted; do not use this in production.", c.baseURL)
+	}
+	c.tlsConfig().InsecureSkipVerify = skip
+}
+
+// LoadCABundle trusts the PEM-encoded CA certificates in path in addition
+// to the system roots
+func (c *Client) LoadCABundle(path string) error {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	config := c.tlsConfig()
+	pool := config.RootCAs
+	if pool == nil {
+		pool, err = x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no certificates found in CA bundle %s", path)
+	}
+	config.RootCAs = pool
+	return nil
+}
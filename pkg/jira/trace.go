@@ -0,0 +1,29 @@
+package jira
+
+import "net/http"
+
+// SetOnRequest sets a hook called before each HTTP attempt of an API
+// request, for tracing. The headers are a copy with the Authorization value
+// redacted. Nil disables the hook.
+func (c *Client) SetOnRequest(hook func(method, url string, headers http.Header)) {
+	c.onRequest = hook
+}
+
+// SetOnResponse sets a hook called with each API response, after the body
+// has been read and decompressed, e.g. to record test fixtures. The body
+// must not be modified. Nil disables the hook.
+func (c *Client) SetOnResponse(hook func(status int, headers http.Header, body []byte)) {
+	c.onResponse = hook
+}
+
+// traceRequest calls the request hook, if set
+func (c *Client) traceRequest(req *http.Request) {
+	if c.onRequest == nil {
+		return
+	}
+	headers := req.Header.Clone()
+	if headers.Get("Authorization") != "" {
+		headers.Set("Authorization", "REDACTED")
+	}
+	c.onRequest(req.Method, req.URL.String(), headers)
+}
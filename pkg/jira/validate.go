@@ -0,0 +1,57 @@
+package jira
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidJQL is returned by ValidateJQL when JIRA rejects the query
+var ErrInvalidJQL = errors.New("invalid JQL")
+
+// ValidateJQL checks a query without fetching any issues, using a search
+// with maxResults=0, and returns the number of matching issues. A query
+// JIRA rejects yields an error wrapping ErrInvalidJQL with JIRA's own
+// messages, e.g. `invalid JQL: Field 'stauts' does not exist`; other
+// failures (auth, network) are returned as is.
+func (c *Client) ValidateJQL(jql string) (int, error) {
+	result, err := c.Search(jql, 0, 0)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+			if messages := apiErr.Messages(); len(messages) > 0 {
+				return 0, fmt.Errorf("%w: %s", ErrInvalidJQL, strings.Join(messages, "; "))
+			}
+			return 0, fmt.Errorf("%w: %s", ErrInvalidJQL, apiErr.Body)
+		}
+		return 0, err
+	}
+	return result.Total, nil
+}
+
+// Messages returns the error messages of a JIRA error response body
+// ({"errorMessages": [...], "errors": {field: message}}), or nil if the
+// body is not in that format
+func (e *APIError) Messages() []string {
+	var body struct {
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(e.Body), &body); err != nil {
+		return nil
+	}
+
+	messages := body.ErrorMessages
+	fields := make([]string, 0, len(body.Errors))
+	for field := range body.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		messages = append(messages, field+": "+body.Errors[field])
+	}
+	return messages
+}
@@ -0,0 +1,274 @@
+// Package jql builds JIRA Query Language strings from typed clauses, taking
+// care of quoting so callers never concatenate user input into queries.
+package jql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DateLayout is the JQL date-time literal format. JIRA interprets these in
+// the searching user's time zone.
+const DateLayout = "2006/01/02 15:04"
+
+// Clause is a single JQL condition
+type Clause interface {
+	String() string
+}
+
+// clause is a rendered condition
+type clause string
+
+func (c clause) String() string { return string(c) }
+
+// Quote renders s as a JQL string literal, escaping quotes and backslashes
+func Quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// quoteAll renders values as a parenthesized list of string literals
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = Quote(value)
+	}
+	return "(" + strings.Join(quoted, ", ") + ")"
+}
+
+// Project matches issues in a project
+func Project(key string) Clause {
+	return clause("project = " + Quote(key))
+}
+
+// Key matches issues with any of the given keys
+func Key(keys ...string) Clause {
+	return clause("key in " + quoteAll(keys))
+}
+
+// Status matches issues in any of the given statuses
+func Status(names ...string) Clause {
+	return clause("status in " + quoteAll(names))
+}
+
+// UpdatedAfter matches issues updated at or after t. JQL has minute
+// precision and uses the searching user's time zone, so t is formatted in
+// its own location; convert it first if needed.
+func UpdatedAfter(t time.Time) Clause {
+	return clause("updated >= " + Quote(t.Format(DateLayout)))
+}
+
+// UpdatedBefore matches issues last updated before t
+func UpdatedBefore(t time.Time) Clause {
+	return clause("updated < " + Quote(t.Format(DateLayout)))
+}
+
+// UpdatedWithin matches issues updated within the last d, using JQL's
+// relative date syntax (e.g. updated >= -90m). d is rounded up to minutes.
+func UpdatedWithin(d time.Duration) Clause {
+	minutes := int64((d + time.Minute - 1) / time.Minute)
+	return clause(fmt.Sprintf("updated >= -%dm", minutes))
+}
+
+// Raw wraps an existing JQL condition in parentheses so it composes safely.
+// The string must not contain an ORDER BY clause.
+func Raw(query string) Clause {
+	return clause("(" + query + ")")
+}
+
+// And joins clauses with AND
+func And(clauses ...Clause) Clause {
+	return join("AND", clauses)
+}
+
+// Or joins clauses with OR
+func Or(clauses ...Clause) Clause {
+	return join("OR", clauses)
+}
+
+// Not negates a clause
+func Not(c Clause) Clause {
+	return clause("NOT (" + c.String() + ")")
+}
+
+// join combines clauses with an operator, parenthesizing when needed
+func join(op string, clauses []Clause) Clause {
+	var parts []string
+	for _, c := range clauses {
+		if c != nil && c.String() != "" {
+			parts = append(parts, c.String())
+		}
+	}
+	switch len(parts) {
+	case 0:
+		return clause("")
+	case 1:
+		return clause(parts[0])
+	default:
+		return clause("(" + strings.Join(parts, " "+op+" ") + ")")
+	}
+}
+
+// Direction is a sort direction
+type Direction string
+
+const (
+	Asc  Direction = "ASC"
+	Desc Direction = "DESC"
+)
+
+// Order is a single ORDER BY term
+type Order struct {
+	Field     string
+	Direction Direction
+}
+
+// OrderBy creates an ORDER BY term
+func OrderBy(field string, dir Direction) Order {
+	return Order{Field: field, Direction: dir}
+}
+
+// fieldPattern matches field names usable unquoted in ORDER BY
+var fieldPattern = regexp.MustCompile(`(?i)^(cf\[\d+\]|[a-z][a-z0-9_.]*)$`)
+
+// orderTermPattern matches a single raw ORDER BY term
+var orderTermPattern = regexp.MustCompile(`(?i)^("[^"]+"|cf\[\d+\]|[a-z][a-z0-9_.]*)(\s+(asc|desc))?$`)
+
+// String renders the term, quoting field names that need it
+func (o Order) String() string {
+	field := o.Field
+	if !fieldPattern.MatchString(field) {
+		field = Quote(field)
+	}
+	if o.Direction == "" {
+		return field
+	}
+	return field + " " + string(o.Direction)
+}
+
+// ValidateOrderBy checks that a raw ORDER BY clause (without the keywords)
+// is a comma-separated list of field names with optional ASC/DESC
+func ValidateOrderBy(orderBy string) error {
+	if strings.TrimSpace(orderBy) == "" {
+		return fmt.Errorf("order by clause is empty")
+	}
+	for _, term := range strings.Split(orderBy, ",") {
+		term = strings.TrimSpace(term)
+		if !orderTermPattern.MatchString(term) {
+			return fmt.Errorf("invalid order by term %q", term)
+		}
+	}
+	return nil
+}
+
+// orderByPattern finds an ORDER BY keyword pair outside string literals
+var orderByPattern = regexp.MustCompile(`(?i)\border\s+by\b`)
+
+// SplitOrderBy separates a query into its condition and ORDER BY clause,
+// ignoring "order by" inside quoted strings
+func SplitOrderBy(query string) (where, orderBy string) {
+	masked := maskLiterals(query)
+	loc := orderByPattern.FindStringIndex(masked)
+	if loc == nil {
+		return strings.TrimSpace(query), ""
+	}
+	return strings.TrimSpace(query[:loc[0]]), strings.TrimSpace(query[loc[1]:])
+}
+
+// maskLiterals replaces the contents of quoted strings with spaces so
+// keyword searches ignore them, preserving byte offsets
+func maskLiterals(query string) string {
+	masked := []byte(query)
+	var quote byte
+	for i := 0; i < len(masked); i++ {
+		c := masked[i]
+		switch {
+		case quote != 0 && c == '\\' && i+1 < len(masked):
+			masked[i], masked[i+1] = ' ', ' '
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			masked[i] = ' '
+		case c == '"' || c == '\'':
+			quote = c
+		}
+	}
+	return string(masked)
+}
+
+// Query is a JQL condition with an optional ordering
+type Query struct {
+	where   []Clause
+	order   []Order
+	rawSort string
+}
+
+// New starts a query whose clauses are joined with AND
+func New(clauses ...Clause) *Query {
+	return &Query{where: clauses}
+}
+
+// Where adds clauses, joined with AND
+func (q *Query) Where(clauses ...Clause) *Query {
+	q.where = append(q.where, clauses...)
+	return q
+}
+
+// OrderBy appends ORDER BY terms
+func (q *Query) OrderBy(orders ...Order) *Query {
+	q.order = append(q.order, orders...)
+	return q
+}
+
+// OrderByClause sets a raw ORDER BY clause such as "created ASC, key",
+// validated when the query is built
+func (q *Query) OrderByClause(orderBy string) *Query {
+	q.rawSort = orderBy
+	return q
+}
+
+// Build renders and validates the query
+func (q *Query) Build() (string, error) {
+	var b strings.Builder
+	b.WriteString(And(q.where...).String())
+
+	var terms []string
+	for _, o := range q.order {
+		if strings.TrimSpace(o.Field) == "" {
+			return "", fmt.Errorf("order by field is empty")
+		}
+		if o.Direction != "" && o.Direction != Asc && o.Direction != Desc {
+			return "", fmt.Errorf("invalid sort direction %q", o.Direction)
+		}
+		terms = append(terms, o.String())
+	}
+	if q.rawSort != "" {
+		if err := ValidateOrderBy(q.rawSort); err != nil {
+			return "", err
+		}
+		terms = append(terms, q.rawSort)
+	}
+
+	if len(terms) > 0 {
+		if b.Len() > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString("ORDER BY " + strings.Join(terms, ", "))
+	}
+	return b.String(), nil
+}
+
+// String renders the query for display, returning "" if it is invalid.
+// Use Build for a query that is sent to JIRA, since an empty query matches
+// every issue.
+func (q *Query) String() string {
+	s, err := q.Build()
+	if err != nil {
+		return ""
+	}
+	return s
+}
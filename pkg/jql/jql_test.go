@@ -0,0 +1,111 @@
+package jql
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuote(t *testing.T) {
+	tests := map[string]string{
+		`PROJ`:              `"PROJ"`,
+		`say "hi"`:          `"say \"hi\""`,
+		`C:\path`:           `"C:\\path"`,
+		`\"`:                `"\\\""`,
+		`x" OR project = Y`: `"x\" OR project = Y"`,
+		``:                  `""`,
+	}
+	for in, want := range tests {
+		if got := Quote(in); got != want {
+			t.Errorf("Quote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestClauses(t *testing.T) {
+	at := time.Date(2024, 3, 5, 14, 7, 59, 0, time.UTC)
+	tests := []struct {
+		name   string
+		clause Clause
+		want   string
+	}{
+		{"project", Project(`A"B`), `project = "A\"B"`},
+		{"keys", Key("PROJ-1", "PROJ-2"), `key in ("PROJ-1", "PROJ-2")`},
+		{"statuses", Status("In Progress", `Won't "Fix"`), `status in ("In Progress", "Won't \"Fix\"")`},
+		{"updated after", UpdatedAfter(at), `updated >= "2024/03/05 14:07"`},
+		{"updated before", UpdatedBefore(at), `updated < "2024/03/05 14:07"`},
+		{"updated within rounds up", UpdatedWithin(90 * time.Second), `updated >= -2m`},
+		{"raw", Raw("a = 1 OR b = 2"), `(a = 1 OR b = 2)`},
+		{"and skips empty", And(Project("A"), nil, And()), `project = "A"`},
+		{"or", Or(Project("A"), Project("B")), `(project = "A" OR project = "B")`},
+		{"not", Not(Project("A")), `NOT (project = "A")`},
+		{"empty and", And(), ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.clause.String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   *Query
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "where and order",
+			query: New(Project("PROJ"), Status("Open")).OrderBy(OrderBy("updated", Asc), OrderBy("Story Points", Desc)),
+			want:  `(project = "PROJ" AND status in ("Open")) ORDER BY updated ASC, "Story Points" DESC`,
+		},
+		{
+			name:  "raw order by",
+			query: New(Project("PROJ")).OrderByClause("created ASC, key"),
+			want:  `project = "PROJ" ORDER BY created ASC, key`,
+		},
+		{
+			name:  "order only",
+			query: New().OrderBy(OrderBy("cf[10020]", "")),
+			want:  `ORDER BY cf[10020]`,
+		},
+		{name: "invalid raw order by", query: New(Project("PROJ")).OrderByClause("key; DROP"), wantErr: true},
+		{name: "injected raw order by", query: New(Project("PROJ")).OrderByClause(`key OR project = X`), wantErr: true},
+		{name: "empty order field", query: New().OrderBy(OrderBy(" ", Asc)), wantErr: true},
+		{name: "invalid direction", query: New().OrderBy(OrderBy("key", "SIDEWAYS")), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.query.Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Build = %s, want %s", got, tt.want)
+			}
+			if tt.wantErr && tt.query.String() != "" {
+				t.Errorf("String = %q for an invalid query, want empty", tt.query.String())
+			}
+		})
+	}
+}
+
+func TestSplitOrderBy(t *testing.T) {
+	tests := []struct {
+		query, where, orderBy string
+	}{
+		{`project = A ORDER BY key`, `project = A`, `key`},
+		{`project = A order  by created DESC`, `project = A`, `created DESC`},
+		{`summary ~ "sort order by date"`, `summary ~ "sort order by date"`, ``},
+		{`summary ~ "quote \" order by" ORDER BY key`, `summary ~ "quote \" order by"`, `key`},
+		{`summary ~ 'order by' ORDER BY key`, `summary ~ 'order by'`, `key`},
+	}
+	for _, tt := range tests {
+		where, orderBy := SplitOrderBy(tt.query)
+		if where != tt.where || orderBy != tt.orderBy {
+			t.Errorf("SplitOrderBy(%q) = %q, %q, want %q, %q", tt.query, where, orderBy, tt.where, tt.orderBy)
+		}
+	}
+}
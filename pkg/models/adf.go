@@ -0,0 +1,341 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ADFNode is a node in an Atlassian Document Format document, the rich text
+// representation used for descriptions and comments on REST API v3
+type ADFNode struct {
+	Type    string         `json:"type"`
+	Version int            `json:"version,omitempty"`
+	Text    string         `json:"text,omitempty"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+	Marks   []ADFMark      `json:"marks,omitempty"`
+	Content []*ADFNode     `json:"content,omitempty"`
+}
+
+// ADFMark is a formatting mark (bold, link, code, ...) applied to a text node
+type ADFMark struct {
+	Type  string         `json:"type"`
+	Attrs map[string]any `json:"attrs,omitempty"`
+}
+
+// ToPlainText renders the document as plain text
+func (n *ADFNode) ToPlainText() string {
+	if n == nil {
+		return ""
+	}
+	return strings.TrimSpace(renderADFBlock(n, false))
+}
+
+// ToMarkdown renders the document as Markdown
+func (n *ADFNode) ToMarkdown() string {
+	if n == nil {
+		return ""
+	}
+	return strings.TrimSpace(renderADFBlock(n, true))
+}
+
+// adfInlineTypes are node types that render inline within a paragraph
+var adfInlineTypes = map[string]bool{
+	"text":       true,
+	"hardBreak":  true,
+	"mention":    true,
+	"emoji":      true,
+	"inlineCard": true,
+	"date":       true,
+	"status":     true,
+}
+
+// renderADFBlocks renders a sequence of nodes separated by blank lines,
+// grouping runs of inline nodes into a single paragraph
+func renderADFBlocks(nodes []*ADFNode, md bool) string {
+	return joinADFBlocks(nodes, md, "\n\n")
+}
+
+// joinADFBlocks renders a sequence of nodes joined by sep
+func joinADFBlocks(nodes []*ADFNode, md bool, sep string) string {
+	var blocks []string
+	var inline []*ADFNode
+
+	flush := func() {
+		if len(inline) > 0 {
+			blocks = append(blocks, renderADFInline(inline, md))
+			inline = nil
+		}
+	}
+
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		if adfInlineTypes[node.Type] {
+			inline = append(inline, node)
+			continue
+		}
+		flush()
+		if block := renderADFBlock(node, md); strings.TrimSpace(block) != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	flush()
+
+	return strings.Join(blocks, sep)
+}
+
+// renderADFBlock renders a single block-level node
+func renderADFBlock(n *ADFNode, md bool) string {
+	switch n.Type {
+	case "paragraph":
+		return renderADFInline(n.Content, md)
+
+	case "heading":
+		text := renderADFInline(n.Content, md)
+		if !md {
+			return text
+		}
+		level := adfIntAttr(n, "level", 1)
+		return strings.Repeat("#", level) + " " + text
+
+	case "bulletList", "orderedList":
+		start := adfIntAttr(n, "order", 1)
+		var items []string
+		for i, item := range n.Content {
+			if item == nil {
+				continue
+			}
+			marker := "- "
+			if n.Type == "orderedList" {
+				marker = fmt.Sprintf("%d. ", start+i)
+			}
+			// List items are rendered tight, without blank lines between blocks
+			body := joinADFBlocks(item.Content, md, "\n")
+			indent := strings.Repeat(" ", len(marker))
+			items = append(items, marker+strings.ReplaceAll(body, "\n", "\n"+indent))
+		}
+		return strings.Join(items, "\n")
+
+	case "codeBlock":
+		text := adfText(n)
+		if !md {
+			return text
+		}
+		language, _ := n.Attrs["language"].(string)
+		return "```" + language + "\n" + text + "\n```"
+
+	case "blockquote":
+		body := renderADFBlocks(n.Content, md)
+		if !md {
+			return body
+		}
+		return "> " + strings.ReplaceAll(body, "\n", "\n> ")
+
+	case "rule":
+		if md {
+			return "---"
+		}
+		return ""
+
+	case "table":
+		var rows []string
+		for i, row := range n.Content {
+			if row == nil {
+				continue
+			}
+			var cells []string
+			for _, cell := range row.Content {
+				if cell == nil {
+					continue
+				}
+				text := strings.ReplaceAll(renderADFBlocks(cell.Content, md), "\n", " ")
+				cells = append(cells, text)
+			}
+			if !md {
+				rows = append(rows, strings.Join(cells, " | "))
+				continue
+			}
+			rows = append(rows, "| "+strings.Join(cells, " | ")+" |")
+			if i == 0 {
+				rows = append(rows, "|"+strings.Repeat(" --- |", len(cells)))
+			}
+		}
+		return strings.Join(rows, "\n")
+
+	case "mediaSingle", "mediaGroup", "media":
+		return ""
+
+	default:
+		// doc, panel, expand and unknown containers render their children
+		if len(n.Content) > 0 {
+			return renderADFBlocks(n.Content, md)
+		}
+		return renderADFInline([]*ADFNode{n}, md)
+	}
+}
+
+// renderADFInline renders inline nodes as a single run of text
+func renderADFInline(nodes []*ADFNode, md bool) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+		switch n.Type {
+		case "text":
+			if md {
+				b.WriteString(applyADFMarks(n.Text, n.Marks))
+			} else {
+				b.WriteString(n.Text)
+			}
+		case "hardBreak":
+			b.WriteString("\n")
+		case "mention", "status":
+			text, _ := n.Attrs["text"].(string)
+			b.WriteString(text)
+		case "emoji":
+			if text, ok := n.Attrs["text"].(string); ok && text != "" {
+				b.WriteString(text)
+			} else if shortName, ok := n.Attrs["shortName"].(string); ok {
+				b.WriteString(shortName)
+			}
+		case "inlineCard":
+			link, _ := n.Attrs["url"].(string)
+			if md && link != "" {
+				b.WriteString("<" + link + ">")
+			} else {
+				b.WriteString(link)
+			}
+		case "date":
+			timestamp, _ := n.Attrs["timestamp"].(string)
+			b.WriteString(timestamp)
+		default:
+			b.WriteString(renderADFInline(n.Content, md))
+		}
+	}
+	return b.String()
+}
+
+// applyADFMarks wraps text in Markdown syntax for its marks
+func applyADFMarks(text string, marks []ADFMark) string {
+	var href string
+	for _, mark := range marks {
+		switch mark.Type {
+		case "code":
+			text = "`" + text + "`"
+		case "strong":
+			text = "**" + text + "**"
+		case "em":
+			text = "*" + text + "*"
+		case "strike":
+			text = "~~" + text + "~~"
+		case "link":
+			href, _ = mark.Attrs["href"].(string)
+		}
+	}
+	if href != "" {
+		text = "[" + text + "](" + href + ")"
+	}
+	return text
+}
+
+// adfText concatenates the raw text of a node's descendants
+func adfText(n *ADFNode) string {
+	if n.Type == "text" {
+		return n.Text
+	}
+	var b strings.Builder
+	for _, child := range n.Content {
+		if child != nil {
+			b.WriteString(adfText(child))
+		}
+	}
+	return b.String()
+}
+
+// adfIntAttr reads a numeric attribute, returning def if absent
+func adfIntAttr(n *ADFNode, name string, def int) int {
+	if value, ok := n.Attrs[name].(float64); ok {
+		return int(value)
+	}
+	return def
+}
+
+// RichText is a text field that is a plain (wiki markup) string on REST API
+// v2 and an ADF document on v3. It round-trips whichever form was received.
+type RichText struct {
+	Text string   // Set when the field was a plain string
+	Doc  *ADFNode // Set when the field was an ADF document
+}
+
+// UnmarshalJSON accepts either a JSON string or an ADF object. Errors are
+// returned unwrapped so type errors can be located to the offending field.
+func (r *RichText) UnmarshalJSON(data []byte) error {
+	*r = RichText{}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil
+	}
+
+	switch trimmed[0] {
+	case '"':
+		return json.Unmarshal(trimmed, &r.Text)
+	case '{':
+	default:
+		return &json.UnmarshalTypeError{Value: jsonKind(trimmed[0]), Type: reflect.TypeOf(*r)}
+	}
+
+	var doc ADFNode
+	if err := json.Unmarshal(trimmed, &doc); err != nil {
+		return err
+	}
+	r.Doc = &doc
+	return nil
+}
+
+// jsonKind names the kind of JSON value starting with c, as in
+// json.UnmarshalTypeError.Value
+func jsonKind(c byte) string {
+	switch c {
+	case '[':
+		return "array"
+	case 't', 'f':
+		return "bool"
+	default:
+		return "number"
+	}
+}
+
+// MarshalJSON writes the field back in the form it was received
+func (r RichText) MarshalJSON() ([]byte, error) {
+	if r.Doc != nil {
+		return json.Marshal(r.Doc)
+	}
+	return json.Marshal(r.Text)
+}
+
+// PlainText returns the field as plain text
+func (r RichText) PlainText() string {
+	if r.Doc != nil {
+		return r.Doc.ToPlainText()
+	}
+	return r.Text
+}
+
+// Markdown returns the field as Markdown. Plain strings are returned as-is.
+func (r RichText) Markdown() string {
+	if r.Doc != nil {
+		return r.Doc.ToMarkdown()
+	}
+	return r.Text
+}
+
+// String returns the field as plain text
+func (r RichText) String() string {
+	return r.PlainText()
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestADFTextConversion(t *testing.T) {
+	tests := []struct {
+		name      string
+		doc       string
+		wantPlain string
+		wantMD    string
+	}{
+		{
+			name: "paragraphs and hard break",
+			doc: `{"type":"doc","content":[
+				{"type":"paragraph","content":[{"type":"text","text":"one"},{"type":"hardBreak"},{"type":"text","text":"two"}]},
+				{"type":"paragraph","content":[{"type":"text","text":"three"}]}]}`,
+			wantPlain: "one\ntwo\n\nthree",
+			wantMD:    "one\ntwo\n\nthree",
+		},
+		{
+			name: "bullet list",
+			doc: `{"type":"doc","content":[{"type":"bulletList","content":[
+				{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"one"}]}]},
+				{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"two"}]}]}]}]}`,
+			wantPlain: "- one\n- two",
+			wantMD:    "- one\n- two",
+		},
+		{
+			name: "ordered list with start",
+			doc: `{"type":"doc","content":[{"type":"orderedList","attrs":{"order":3},"content":[
+				{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"a"}]}]},
+				{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"b"}]}]}]}]}`,
+			wantPlain: "3. a\n4. b",
+			wantMD:    "3. a\n4. b",
+		},
+		{
+			name: "nested list",
+			doc: `{"type":"doc","content":[{"type":"bulletList","content":[
+				{"type":"listItem","content":[
+					{"type":"paragraph","content":[{"type":"text","text":"a"}]},
+					{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"b"}]}]}]}]}]}]}`,
+			wantPlain: "- a\n  - b",
+			wantMD:    "- a\n  - b",
+		},
+		{
+			name: "link",
+			doc: `{"type":"doc","content":[{"type":"paragraph","content":[
+				{"type":"text","text":"see "},
+				{"type":"text","text":"docs","marks":[{"type":"link","attrs":{"href":"https://example.com/docs"}}]}]}]}`,
+			wantPlain: "see docs",
+			wantMD:    "see [docs](https://example.com/docs)",
+		},
+		{
+			name:      "inline card",
+			doc:       `{"type":"doc","content":[{"type":"paragraph","content":[{"type":"inlineCard","attrs":{"url":"https://example.com"}}]}]}`,
+			wantPlain: "https://example.com",
+			wantMD:    "<https://example.com>",
+		},
+		{
+			name: "code block",
+			doc: `{"type":"doc","content":[{"type":"codeBlock","attrs":{"language":"go"},"content":[
+				{"type":"text","text":"x := 1\nfmt.Println(x)"}]}]}`,
+			wantPlain: "x := 1\nfmt.Println(x)",
+			wantMD:    "```go\nx := 1\nfmt.Println(x)\n```",
+		},
+		{
+			name: "inline marks",
+			doc: `{"type":"doc","content":[{"type":"paragraph","content":[
+				{"type":"text","text":"run","marks":[{"type":"code"}]},
+				{"type":"text","text":" "},
+				{"type":"text","text":"now","marks":[{"type":"strong"}]}]}]}`,
+			wantPlain: "run now",
+			wantMD:    "`run` **now**",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var text RichText
+			if err := json.Unmarshal([]byte(tt.doc), &text); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if got := text.PlainText(); got != tt.wantPlain {
+				t.Errorf("PlainText = %q, want %q", got, tt.wantPlain)
+			}
+			if got := text.Markdown(); got != tt.wantMD {
+				t.Errorf("Markdown = %q, want %q", got, tt.wantMD)
+			}
+		})
+	}
+}
+
+func TestRichTextPlainString(t *testing.T) {
+	var text RichText
+	if err := json.Unmarshal([]byte(`"h1. Title"`), &text); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if text.PlainText() != "h1. Title" || text.Doc != nil {
+		t.Errorf("RichText = %+v, want the plain string", text)
+	}
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Custom field types of the JIRA Software (Agile) fields
+const (
+	SprintFieldType   = "com.pyxis.greenhopper.jira:gh-sprint"
+	EpicLinkFieldType = "com.pyxis.greenhopper.jira:gh-epic-link"
+)
+
+// Sprint is a JIRA Software sprint, as returned by the Agile API and in the
+// sprint custom field
+type Sprint struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	State         string `json:"state"` // future, active or closed
+	StartDate     string `json:"startDate,omitempty"`
+	EndDate       string `json:"endDate,omitempty"`
+	CompleteDate  string `json:"completeDate,omitempty"`
+	OriginBoardID int64  `json:"originBoardId,omitempty"`
+	Goal          string `json:"goal,omitempty"`
+}
+
+// SprintPage is one page of the Agile API's board sprint list
+type SprintPage struct {
+	StartAt    int      `json:"startAt"`
+	MaxResults int      `json:"maxResults"`
+	IsLast     bool     `json:"isLast"`
+	Values     []Sprint `json:"values"`
+}
+
+// AgileFieldIDs returns the IDs of the sprint and epic link custom fields
+// among the instance's field definitions (see Client.GetFields); either is
+// "" when JIRA Software is not installed
+func AgileFieldIDs(fields []FieldMeta) (sprintField, epicLinkField string) {
+	for _, field := range fields {
+		if field.Schema == nil {
+			continue
+		}
+		switch field.Schema.Custom {
+		case SprintFieldType:
+			sprintField = field.ID
+		case EpicLinkFieldType:
+			epicLinkField = field.ID
+		}
+	}
+	return sprintField, epicLinkField
+}
+
+// Sprints parses the sprint custom field with the given ID. JIRA Cloud
+// returns sprint objects; JIRA Server and Data Center return strings like
+// "com.atlassian.greenhopper.service.sprint.Sprint@1a2b[id=1,state=CLOSED,
+// name=Sprint 1,...]", which are parsed too. Missing fields and a nil
+// receiver yield no sprints.
+func (f *IssueFields) Sprints(fieldID string) ([]Sprint, error) {
+	if f == nil {
+		return nil, nil
+	}
+	raw, ok := f.Custom[fieldID]
+	if !ok {
+		return nil, nil
+	}
+
+	var values []json.RawMessage
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, fmt.Errorf("sprint field %s is not a list: %w", fieldID, err)
+	}
+
+	sprints := make([]Sprint, 0, len(values))
+	for _, value := range values {
+		var legacy string
+		if err := json.Unmarshal(value, &legacy); err == nil {
+			sprint, err := parseLegacySprint(legacy)
+			if err != nil {
+				return nil, err
+			}
+			sprints = append(sprints, sprint)
+			continue
+		}
+
+		var sprint Sprint
+		if err := json.Unmarshal(value, &sprint); err != nil {
+			return nil, fmt.Errorf("failed to parse sprint: %w", err)
+		}
+		sprints = append(sprints, sprint)
+	}
+	return sprints, nil
+}
+
+// legacySprintAttr matches the attribute names of the legacy sprint string
+// format. Only known names are matched, as values (e.g. names and goals)
+// may themselves contain commas and equals signs.
+var legacySprintAttr = regexp.MustCompile(`(?:\[|,)(id|rapidViewId|state|name|goal|startDate|endDate|completeDate|activatedDate|sequence|synced|autoStartStop|incompleteIssuesDestinationId)=`)
+
+// parseLegacySprint parses a sprint in the legacy toString format
+func parseLegacySprint(s string) (Sprint, error) {
+	open, end := strings.Index(s, "["), strings.LastIndex(s, "]")
+	if open < 0 || end < open {
+		return Sprint{}, fmt.Errorf("unrecognized sprint value %q", s)
+	}
+	body := s[open:end]
+
+	attrs := map[string]string{}
+	matches := legacySprintAttr.FindAllStringSubmatchIndex(body, -1)
+	for i, m := range matches {
+		valueEnd := len(body)
+		if i+1 < len(matches) {
+			valueEnd = matches[i+1][0]
+		}
+		value := body[m[1]:valueEnd]
+		if value == "<null>" {
+			value = ""
+		}
+		attrs[body[m[2]:m[3]]] = value
+	}
+
+	sprint := Sprint{
+		Name:         attrs["name"],
+		State:        strings.ToLower(attrs["state"]),
+		StartDate:    attrs["startDate"],
+		EndDate:      attrs["endDate"],
+		CompleteDate: attrs["completeDate"],
+		Goal:         attrs["goal"],
+	}
+	var err error
+	if sprint.ID, err = strconv.ParseInt(attrs["id"], 10, 64); err != nil {
+		return Sprint{}, fmt.Errorf("sprint value %q has no valid id", s)
+	}
+	if board := attrs["rapidViewId"]; board != "" {
+		sprint.OriginBoardID, _ = strconv.ParseInt(board, 10, 64)
+	}
+	return sprint, nil
+}
+
+// EpicKey returns the key of the issue's epic: the value of the epic link
+// custom field with the given ID (JIRA Server and Data Center), or else the
+// parent when it is an epic (JIRA Cloud). It returns "" if there is none.
+func (f *IssueFields) EpicKey(epicLinkField string) string {
+	if f == nil {
+		return ""
+	}
+	if raw, ok := f.Custom[epicLinkField]; ok {
+		var key string
+		if err := json.Unmarshal(raw, &key); err == nil && key != "" {
+			return key
+		}
+	}
+	if f.Parent != nil && f.Parent.Fields != nil && f.Parent.Fields.IssueType != nil &&
+		strings.EqualFold(f.Parent.Fields.IssueType.Name, "Epic") {
+		return f.Parent.Key
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseLegacySprint(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Sprint
+		wantErr bool
+	}{
+		{
+			name:  "closed sprint",
+			value: "com.atlassian.greenhopper.service.sprint.Sprint@1a2b[id=12,rapidViewId=3,state=CLOSED,name=Sprint 12,startDate=2024-01-01T09:00:00.000Z,endDate=2024-01-15T09:00:00.000Z,completeDate=2024-01-15T10:00:00.000Z,activatedDate=2024-01-01T09:00:00.000Z,sequence=12,goal=Ship it,synced=false,autoStartStop=false,incompleteIssuesDestinationId=<null>]",
+			want: Sprint{ID: 12, OriginBoardID: 3, State: "closed", Name: "Sprint 12", Goal: "Ship it",
+				StartDate: "2024-01-01T09:00:00.000Z", EndDate: "2024-01-15T09:00:00.000Z", CompleteDate: "2024-01-15T10:00:00.000Z"},
+		},
+		{
+			name:  "future sprint with null dates",
+			value: "com.atlassian.greenhopper.service.sprint.Sprint@ff[id=13,rapidViewId=3,state=FUTURE,name=Sprint 13,startDate=<null>,endDate=<null>,completeDate=<null>,sequence=13,goal=<null>]",
+			want:  Sprint{ID: 13, OriginBoardID: 3, State: "future", Name: "Sprint 13"},
+		},
+		{
+			name:  "commas and equals in name and goal",
+			value: "Sprint@1[id=7,state=ACTIVE,name=Team A, week=2,goal=a=b, then c]",
+			want:  Sprint{ID: 7, State: "active", Name: "Team A, week=2", Goal: "a=b, then c"},
+		},
+		{name: "missing id", value: "Sprint@1[state=ACTIVE,name=No ID]", wantErr: true},
+		{name: "not a sprint", value: "Sprint 12", wantErr: true},
+		{name: "unclosed", value: "Sprint@1]id=1[", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLegacySprint(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSprints(t *testing.T) {
+	const field = "customfield_10020"
+	tests := []struct {
+		name    string
+		fields  *IssueFields
+		want    []Sprint
+		wantErr bool
+	}{
+		{name: "nil fields"},
+		{name: "no custom fields", fields: &IssueFields{}},
+		{
+			name:   "cloud objects",
+			fields: &IssueFields{Custom: map[string]json.RawMessage{field: json.RawMessage(`[{"id":1,"name":"S1","state":"closed","boardId":2},{"id":2,"name":"S2","state":"active"}]`)}},
+			want:   []Sprint{{ID: 1, Name: "S1", State: "closed"}, {ID: 2, Name: "S2", State: "active"}},
+		},
+		{
+			name:   "server strings",
+			fields: &IssueFields{Custom: map[string]json.RawMessage{field: json.RawMessage(`["Sprint@1[id=5,state=ACTIVE,name=S5]"]`)}},
+			want:   []Sprint{{ID: 5, Name: "S5", State: "active"}},
+		},
+		{name: "empty list", fields: &IssueFields{Custom: map[string]json.RawMessage{field: json.RawMessage(`[]`)}}, want: []Sprint{}},
+		{name: "not a list", fields: &IssueFields{Custom: map[string]json.RawMessage{field: json.RawMessage(`{"id":1}`)}}, wantErr: true},
+		{name: "bad legacy string", fields: &IssueFields{Custom: map[string]json.RawMessage{field: json.RawMessage(`["Sprint 5"]`)}}, wantErr: true},
+		{name: "bad object", fields: &IssueFields{Custom: map[string]json.RawMessage{field: json.RawMessage(`[{"id":"x"}]`)}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fields.Sprints(field)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEpicKey(t *testing.T) {
+	const field = "customfield_10014"
+	parent := func(issueType string) *LinkedIssue {
+		return &LinkedIssue{Key: "PROJ-1", Fields: &LinkedIssueFields{IssueType: &IssueType{Name: issueType}}}
+	}
+	tests := []struct {
+		name   string
+		fields *IssueFields
+		want   string
+	}{
+		{name: "nil fields"},
+		{name: "no epic", fields: &IssueFields{}},
+		{name: "epic link", fields: &IssueFields{Custom: map[string]json.RawMessage{field: json.RawMessage(`"PROJ-9"`)}}, want: "PROJ-9"},
+		{name: "epic link wins over parent", fields: &IssueFields{Custom: map[string]json.RawMessage{field: json.RawMessage(`"PROJ-9"`)}, Parent: parent("Epic")}, want: "PROJ-9"},
+		{name: "epic parent", fields: &IssueFields{Parent: parent("epic")}, want: "PROJ-1"},
+		{name: "story parent", fields: &IssueFields{Parent: parent("Story")}},
+		{name: "parent without fields", fields: &IssueFields{Parent: &LinkedIssue{Key: "PROJ-1"}}},
+		{name: "non-string epic link", fields: &IssueFields{Custom: map[string]json.RawMessage{field: json.RawMessage(`{"key":"PROJ-9"}`)}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fields.EpicKey(field); got != tt.want {
+				t.Errorf("EpicKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgileFieldIDs(t *testing.T) {
+	fields := []FieldMeta{
+		{ID: "summary", Schema: &FieldSchema{Type: "string", System: "summary"}},
+		{ID: "customfield_10001"},
+		{ID: "customfield_10020", Schema: &FieldSchema{Type: "array", Custom: SprintFieldType}},
+		{ID: "customfield_10014", Schema: &FieldSchema{Type: "any", Custom: EpicLinkFieldType}},
+	}
+	sprint, epic := AgileFieldIDs(fields)
+	if sprint != "customfield_10020" || epic != "customfield_10014" {
+		t.Errorf("AgileFieldIDs = %q, %q", sprint, epic)
+	}
+	if sprint, epic := AgileFieldIDs(fields[:2]); sprint != "" || epic != "" {
+		t.Errorf("AgileFieldIDs without JIRA Software = %q, %q", sprint, epic)
+	}
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// customFieldPrefix marks the IDs of JIRA custom fields
+const customFieldPrefix = "customfield_"
+
+// issueFields has the fields of IssueFields without its JSON methods
+type issueFields IssueFields
+
+// UnmarshalJSON decodes the known fields and keeps every customfield_*
+// value in Custom as raw JSON
+func (f *IssueFields) UnmarshalJSON(data []byte) error {
+	var known issueFields
+	if err := json.Unmarshal(data, &known); err != nil {
+		return locateTypeError(data, err)
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for id, value := range all {
+		if !strings.HasPrefix(id, customFieldPrefix) || bytes.Equal(value, []byte("null")) {
+			continue
+		}
+		if known.Custom == nil {
+			known.Custom = map[string]json.RawMessage{}
+		}
+		known.Custom[id] = value
+	}
+
+	*f = IssueFields(known)
+	return nil
+}
+
+// locateTypeError fills in the field of a type error returned by a
+// field's own UnmarshalJSON (e.g. RichText's), which the decoder leaves
+// without a path, by decoding the fields one at a time
+func locateTypeError(data []byte, err error) error {
+	typeErr, ok := err.(*json.UnmarshalTypeError)
+	if !ok || typeErr.Field != "" {
+		return err
+	}
+	var all map[string]json.RawMessage
+	if json.Unmarshal(data, &all) != nil {
+		return err
+	}
+	ids := make([]string, 0, len(all))
+	for id := range all {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		single, _ := json.Marshal(map[string]json.RawMessage{id: all[id]})
+		var known issueFields
+		if json.Unmarshal(single, &known) != nil {
+			typeErr.Struct = "IssueFields"
+			typeErr.Field = id
+			break
+		}
+	}
+	return err
+}
+
+// MarshalJSON encodes the known fields followed by the custom fields,
+// sorted by ID
+func (f IssueFields) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(issueFields(f))
+	if err != nil || len(f.Custom) == 0 {
+		return data, err
+	}
+
+	ids := make([]string, 0, len(f.Custom))
+	for id := range f.Custom {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var buf bytes.Buffer
+	buf.Write(data[:len(data)-1]) // Drop the closing brace
+	for _, id := range ids {
+		if !json.Valid(f.Custom[id]) {
+			return nil, fmt.Errorf("custom field %s is not valid JSON", id)
+		}
+		name, _ := json.Marshal(id)
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(f.Custom[id])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// CustomValue decodes a custom field into a generic value. Numbers decode
+// as json.Number rather than float64, so large values such as IDs beyond
+// 2^53 keep their precision. A missing field returns nil.
+func (f *IssueFields) CustomValue(id string) (any, error) {
+	raw, ok := f.Custom[id]
+	if !ok {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", id, err)
+	}
+	return value, nil
+}
+
+// CustomInt64 returns a numeric custom field as an int64. It accepts a
+// JSON number or a string holding one, and fails for missing fields,
+// fractions and values outside the int64 range rather than rounding.
+func (f *IssueFields) CustomInt64(id string) (int64, error) {
+	value, err := f.CustomValue(id)
+	if err != nil {
+		return 0, err
+	}
+
+	var number json.Number
+	switch v := value.(type) {
+	case json.Number:
+		number = v
+	case string:
+		number = json.Number(strings.TrimSpace(v))
+	case nil:
+		return 0, fmt.Errorf("custom field %s is not set", id)
+	default:
+		return 0, fmt.Errorf("custom field %s is not a number", id)
+	}
+
+	n, err := number.Int64()
+	if err != nil {
+		return 0, fmt.Errorf("custom field %s is not an int64: %w", id, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCustomFieldLargeNumberRoundTrip(t *testing.T) {
+	// 2^53 + 1 cannot be represented as a float64
+	const big = "9007199254740993"
+	input := `{"summary":"s","customfield_10001":` + big + `,"customfield_10002":{"id":` + big + `},"customfield_10003":null}`
+
+	var fields IssueFields
+	if err := json.Unmarshal([]byte(input), &fields); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	n, err := fields.CustomInt64("customfield_10001")
+	if err != nil {
+		t.Fatalf("CustomInt64: %v", err)
+	}
+	if n != 9007199254740993 {
+		t.Errorf("CustomInt64 = %d, want %s", n, big)
+	}
+	if _, ok := fields.Custom["customfield_10003"]; ok {
+		t.Errorf("null custom field was kept")
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if !strings.Contains(string(data), `"customfield_10001":`+big) {
+		t.Errorf("marshaled fields lost the value: %s", data)
+	}
+	if !strings.Contains(string(data), `"customfield_10002":{"id":`+big+`}`) {
+		t.Errorf("marshaled fields lost the nested value: %s", data)
+	}
+
+	// A second round trip must be stable
+	var again IssueFields
+	if err := json.Unmarshal(data, &again); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	value, err := again.CustomValue("customfield_10001")
+	if err != nil {
+		t.Fatalf("CustomValue: %v", err)
+	}
+	if number, ok := value.(json.Number); !ok || number.String() != big {
+		t.Errorf("CustomValue = %#v, want json.Number %s", value, big)
+	}
+}
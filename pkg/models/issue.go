@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Issue represents a JIRA issue without history
 type Issue struct {
@@ -13,21 +16,187 @@ type Issue struct {
 // IssueWithHistory includes the changelog
 type IssueWithHistory struct {
 	Issue
-	Changelog *Changelog `json:"changelog,omitempty"`
+	Changelog      *Changelog      `json:"changelog,omitempty"`
+	RenderedFields *RenderedFields `json:"renderedFields,omitempty"`
+}
+
+// RenderedFields holds JIRA's server-rendered HTML for rich text fields,
+// returned with expand=renderedFields
+type RenderedFields struct {
+	Description string               `json:"description,omitempty"`
+	Comment     *RenderedCommentPage `json:"comment,omitempty"`
+}
+
+// RenderedCommentPage holds the rendered comments of an issue
+type RenderedCommentPage struct {
+	Comments []RenderedComment `json:"comments"`
+}
+
+// RenderedComment is the HTML body of a comment, matched to the raw comment by ID
+type RenderedComment struct {
+	ID   string `json:"id"`
+	Body string `json:"body"`
 }
 
 // IssueFields contains all JIRA fields
 type IssueFields struct {
-	Summary        string     `json:"summary"`
-	Description    string     `json:"description"`
-	IssueType      *IssueType `json:"issuetype"`
-	Status         *Status    `json:"status"`
-	Priority       *Priority  `json:"priority,omitempty"`
-	Assignee       *User      `json:"assignee,omitempty"`
-	Creator        *User      `json:"creator"`
-	Created        string     `json:"created"`
-	Updated        string     `json:"updated"`
-	ResolutionDate *string    `json:"resolutiondate,omitempty"`
+	Summary         string        `json:"summary"`
+	Description     RichText      `json:"description"`
+	IssueType       *IssueType    `json:"issuetype"`
+	Status          *Status       `json:"status"`
+	Priority        *Priority     `json:"priority,omitempty"`
+	Assignee        *User         `json:"assignee,omitempty"`
+	Creator         *User         `json:"creator"`
+	Reporter        *User         `json:"reporter,omitempty"`
+	Created         string        `json:"created"`
+	Updated         string        `json:"updated"`
+	ResolutionDate  *string       `json:"resolutiondate,omitempty"`
+	Comment         *CommentPage  `json:"comment,omitempty"`
+	Labels          []string      `json:"labels"`
+	Components      []Component   `json:"components"`
+	FixVersions     []Version     `json:"fixVersions"`
+	AffectsVersions []Version     `json:"versions"`
+	Attachments     []Attachment  `json:"attachment,omitempty"`
+	IssueLinks      []IssueLink   `json:"issuelinks,omitempty"`
+	Subtasks        []LinkedIssue `json:"subtasks,omitempty"`
+	Parent          *LinkedIssue  `json:"parent,omitempty"`
+
+	// Custom holds the customfield_* values verbatim, so numbers keep
+	// their full precision; read them with CustomValue or CustomInt64
+	Custom map[string]json.RawMessage `json:"-"`
+}
+
+// IssueLink is a link between two issues, such as "blocks" or "duplicates".
+// Exactly one of InwardIssue and OutwardIssue is set: the other end of the
+// link from the issue it belongs to.
+type IssueLink struct {
+	ID           string        `json:"id"`
+	Type         IssueLinkType `json:"type"`
+	InwardIssue  *LinkedIssue  `json:"inwardIssue,omitempty"`
+	OutwardIssue *LinkedIssue  `json:"outwardIssue,omitempty"`
+}
+
+// IssueLinkType names a kind of issue link in each direction
+type IssueLinkType struct {
+	Name    string `json:"name"`
+	Inward  string `json:"inward"`
+	Outward string `json:"outward"`
+}
+
+// LinkedIssue is the summary of another issue embedded in links, subtasks
+// and parent fields
+type LinkedIssue struct {
+	ID     string             `json:"id"`
+	Key    string             `json:"key"`
+	Fields *LinkedIssueFields `json:"fields,omitempty"`
+}
+
+// LinkedIssueFields are the fields JIRA includes for a linked issue
+type LinkedIssueFields struct {
+	Summary   string     `json:"summary"`
+	Status    *Status    `json:"status,omitempty"`
+	Priority  *Priority  `json:"priority,omitempty"`
+	IssueType *IssueType `json:"issuetype,omitempty"`
+}
+
+// LinkedKeys returns the keys of the issues linked to this one: linked
+// issues, subtasks and the parent, without duplicates
+func (f *IssueFields) LinkedKeys() []string {
+	if f == nil {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var keys []string
+	add := func(linked *LinkedIssue) {
+		if linked != nil && linked.Key != "" && !seen[linked.Key] {
+			seen[linked.Key] = true
+			keys = append(keys, linked.Key)
+		}
+	}
+	for _, link := range f.IssueLinks {
+		add(link.InwardIssue)
+		add(link.OutwardIssue)
+	}
+	for i := range f.Subtasks {
+		add(&f.Subtasks[i])
+	}
+	add(f.Parent)
+	return keys
+}
+
+// Attachment describes a file attached to an issue
+type Attachment struct {
+	ID       string `json:"id"`
+	Filename string `json:"filename"`
+	Author   *User  `json:"author,omitempty"`
+	Created  string `json:"created"`
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Content  string `json:"content"` // Download URL
+}
+
+// RemoteLink is a link from an issue to an external system, such as a
+// pull request or a wiki page
+type RemoteLink struct {
+	ID           int64                  `json:"id"`
+	GlobalID     string                 `json:"globalId,omitempty"`
+	Relationship string                 `json:"relationship,omitempty"`
+	Application  *RemoteLinkApplication `json:"application,omitempty"`
+	Object       RemoteLinkObject       `json:"object"`
+}
+
+// RemoteLinkApplication identifies the system a remote link points to
+type RemoteLinkApplication struct {
+	Type string `json:"type,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// RemoteLinkObject describes the linked resource
+type RemoteLinkObject struct {
+	URL     string `json:"url"`
+	Title   string `json:"title"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// AvailableTransition is a workflow transition the fetching user could
+// perform on an issue in its current status
+type AvailableTransition struct {
+	ID   string  `json:"id"`
+	Name string  `json:"name"`
+	To   *Status `json:"to,omitempty"` // Status the transition leads to
+}
+
+// Version represents a project version (release)
+type Version struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Released    bool    `json:"released"`
+	ReleaseDate *string `json:"releaseDate,omitempty"` // Absent on unreleased versions
+}
+
+// Component represents a project component
+type Component struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// CommentPage contains the comments returned with an issue
+type CommentPage struct {
+	StartAt    int       `json:"startAt"`
+	MaxResults int       `json:"maxResults"`
+	Total      int       `json:"total"`
+	Comments   []Comment `json:"comments"`
+}
+
+// Comment represents a single issue comment
+type Comment struct {
+	ID           string   `json:"id"`
+	Author       *User    `json:"author"`
+	UpdateAuthor *User    `json:"updateAuthor,omitempty"`
+	Body         RichText `json:"body"`
+	Created      string   `json:"created"`
+	Updated      string   `json:"updated"`
 }
 
 // Changelog contains issue history
@@ -56,17 +225,56 @@ type HistoryItem struct {
 	ToString   *string `json:"toString"`
 }
 
-// User represents a JIRA user
+// ChangelogPage is a page from the dedicated changelog endpoint
+type ChangelogPage struct {
+	StartAt    int       `json:"startAt"`
+	MaxResults int       `json:"maxResults"`
+	Total      int       `json:"total"`
+	IsLast     bool      `json:"isLast"`
+	Values     []History `json:"values"`
+}
+
+// FieldChange is a single change to one field together with the history
+// entry it belongs to
+type FieldChange struct {
+	HistoryItem
+	HistoryID string `json:"historyId"`
+	Author    *User  `json:"author"`
+	Created   string `json:"created"`
+}
+
+// User represents a JIRA user. Server and Data Center identify users by
+// Name and Key; Cloud uses AccountID and usually omits both.
 type User struct {
-	Name        string `json:"name"`
-	Key         string `json:"key"`
-	DisplayName string `json:"displayName"`
+	Name         string `json:"name,omitempty"`
+	Key          string `json:"key,omitempty"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress,omitempty"`
+	AccountID    string `json:"accountId,omitempty"`
+}
+
+// Identifier returns the most stable identifier available for the user:
+// the Cloud account ID, then the Server user key, then the username
+func (u *User) Identifier() string {
+	if u == nil {
+		return ""
+	}
+	if u.AccountID != "" {
+		return u.AccountID
+	}
+	if u.Key != "" {
+		return u.Key
+	}
+	return u.Name
 }
 
-// Status represents an issue status
+// Status represents an issue status. StatusCategory was added after caches
+// were first written; it is part of the content hash used to skip unchanged
+// issues, so the first sync after upgrading rewrites records that have it.
 type Status struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	StatusCategory *StatusCategory `json:"statusCategory,omitempty"`
 }
 
 // Priority represents an issue priority
@@ -81,17 +289,85 @@ type IssueType struct {
 	Name string `json:"name"`
 }
 
+// Project represents a JIRA project
+type Project struct {
+	ID   string `json:"id"`
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	Lead *User  `json:"lead,omitempty"`
+}
+
+// ProjectPage represents a page of results from the paginated project search
+type ProjectPage struct {
+	StartAt    int       `json:"startAt"`
+	MaxResults int       `json:"maxResults"`
+	Total      int       `json:"total"`
+	IsLast     bool      `json:"isLast"`
+	Values     []Project `json:"values"`
+}
+
+// Filter represents a saved JIRA filter
+type Filter struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	JQL     string `json:"jql"`
+	Owner   *User  `json:"owner,omitempty"`
+	ViewURL string `json:"viewUrl,omitempty"`
+}
+
+// FieldMeta describes a system or custom field defined on the instance
+type FieldMeta struct {
+	ID     string       `json:"id"`
+	Name   string       `json:"name"`
+	Custom bool         `json:"custom"`
+	Schema *FieldSchema `json:"schema,omitempty"`
+}
+
+// FieldSchema describes the type of a field's values
+type FieldSchema struct {
+	Type     string `json:"type"`
+	Items    string `json:"items,omitempty"`
+	System   string `json:"system,omitempty"`
+	Custom   string `json:"custom,omitempty"`
+	CustomID int64  `json:"customId,omitempty"`
+}
+
 // CachedIssue wraps the JIRA issue with cache metadata
 type CachedIssue struct {
 	CacheMetadata CacheMetadata     `json:"_cache_metadata"`
 	JiraData      *IssueWithHistory `json:"jira_data"`
 }
 
+// CurrentSchemaVersion is the version of the cached record format written by
+// this release. Records without a version are treated as version 0.
+//
+// Version 2 moved remote links and available transitions, which JIRA serves
+// from separate endpoints, out of jira_data into the cache metadata.
+const CurrentSchemaVersion = 2
+
 // CacheMetadata contains information about when and how the issue was cached
 type CacheMetadata struct {
-	FetchedAt         time.Time `json:"fetched_at"`
-	FetchedBy         string    `json:"fetched_by"`
-	APICallDurationMS int64     `json:"api_call_duration_ms"`
+	SchemaVersion     int         `json:"schema_version,omitempty"`
+	FetchedAt         time.Time   `json:"fetched_at"`
+	FetchedBy         string      `json:"fetched_by"`
+	APICallDurationMS int64       `json:"api_call_duration_ms"`
+	ETag              string      `json:"etag,omitempty"`
+	LastModified      string      `json:"last_modified,omitempty"`
+	Renamed           *RenameInfo `json:"renamed,omitempty"`
+	ContentHash       string      `json:"content_hash,omitempty"`
+
+	// Data fetched from endpoints other than the issue's own, kept out of
+	// jira_data so it holds only what JIRA returned for the issue
+	RemoteLinks []RemoteLink          `json:"remote_links,omitempty"`
+	Transitions []AvailableTransition `json:"available_transitions,omitempty"`
+}
+
+// RenameInfo records that an issue was fetched under a key it no longer has,
+// typically because it was moved to another project
+type RenameInfo struct {
+	FromKey    string    `json:"from_key"`
+	ToKey      string    `json:"to_key"`
+	DetectedAt time.Time `json:"detected_at"`
 }
 
 // SearchResult represents the result of a JIRA search
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// jiraTimeLayouts are the timestamp formats returned by the JIRA REST API
+var jiraTimeLayouts = []string{
+	"2006-01-02T15:04:05.000-0700",
+	"2006-01-02T15:04:05-0700",
+	time.RFC3339Nano,
+}
+
+// ParseTime parses a JIRA timestamp such as "2024-01-15T10:30:00.000+0000"
+func ParseTime(value string) (time.Time, error) {
+	for _, layout := range jiraTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized JIRA timestamp %q", value)
+}
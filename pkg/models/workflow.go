@@ -0,0 +1,46 @@
+package models
+
+// Status category keys, the fixed workflow stages every status belongs to
+const (
+	StatusCategoryToDo       = "new"
+	StatusCategoryInProgress = "indeterminate"
+	StatusCategoryDone       = "done"
+)
+
+// StatusCategory is the workflow stage of a status. Key is one of the
+// StatusCategory constants; Name is the localized display name.
+type StatusCategory struct {
+	ID        int    `json:"id"`
+	Key       string `json:"key"`
+	Name      string `json:"name"`
+	ColorName string `json:"colorName,omitempty"`
+}
+
+// IsDone reports whether the status is in the done category
+func (s *Status) IsDone() bool {
+	return s != nil && s.StatusCategory != nil && s.StatusCategory.Key == StatusCategoryDone
+}
+
+// IssueTypeStatuses is the set of statuses an issue type's workflow uses in
+// a project, as returned by /rest/api/2/project/{project}/statuses
+type IssueTypeStatuses struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Subtask  bool     `json:"subtask"`
+	Statuses []Status `json:"statuses"`
+}
+
+// StatusCategoryKeys maps each status name used by the given issue types to
+// its category key, for classifying changelog status transitions, which
+// carry only the status name
+func StatusCategoryKeys(types []IssueTypeStatuses) map[string]string {
+	categories := make(map[string]string)
+	for _, issueType := range types {
+		for _, status := range issueType.Statuses {
+			if status.StatusCategory != nil {
+				categories[status.Name] = status.StatusCategory.Key
+			}
+		}
+	}
+	return categories
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// projectStatusesResponse is a trimmed /rest/api/2/project/{project}/statuses
+// response
+const projectStatusesResponse = `[
+  {
+    "self": "https://jira.example.com/rest/api/2/issuetype/1",
+    "id": "1",
+    "name": "Bug",
+    "subtask": false,
+    "statuses": [
+      {
+        "self": "https://jira.example.com/rest/api/2/status/1",
+        "description": "The issue is open and ready for the assignee to start work on it.",
+        "iconUrl": "https://jira.example.com/images/icons/statuses/open.png",
+        "name": "Open",
+        "id": "1",
+        "statusCategory": {"self": "https://jira.example.com/rest/api/2/statuscategory/2", "id": 2, "key": "new", "colorName": "blue-gray", "name": "To Do"}
+      },
+      {
+        "name": "In Progress",
+        "id": "3",
+        "statusCategory": {"id": 4, "key": "indeterminate", "colorName": "yellow", "name": "In Progress"}
+      },
+      {
+        "name": "Closed",
+        "id": "6",
+        "statusCategory": {"id": 3, "key": "done", "colorName": "green", "name": "Done"}
+      }
+    ]
+  },
+  {
+    "id": "5",
+    "name": "Sub-task",
+    "subtask": true,
+    "statuses": [
+      {"name": "Open", "id": "1", "statusCategory": {"id": 2, "key": "new", "name": "To Do"}},
+      {"name": "Legacy", "id": "99"}
+    ]
+  }
+]`
+
+func TestDecodeProjectStatuses(t *testing.T) {
+	var types []IssueTypeStatuses
+	if err := json.Unmarshal([]byte(projectStatusesResponse), &types); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(types) != 2 || types[0].Name != "Bug" || !types[1].Subtask || len(types[0].Statuses) != 3 {
+		t.Fatalf("decoded %+v", types)
+	}
+	want := &StatusCategory{ID: 2, Key: StatusCategoryToDo, Name: "To Do", ColorName: "blue-gray"}
+	if got := types[0].Statuses[0].StatusCategory; !reflect.DeepEqual(got, want) {
+		t.Errorf("Open category = %+v, want %+v", got, want)
+	}
+	if types[1].Statuses[1].StatusCategory != nil {
+		t.Errorf("uncategorized status decoded with a category")
+	}
+
+	categories := StatusCategoryKeys(types)
+	wantKeys := map[string]string{"Open": "new", "In Progress": "indeterminate", "Closed": "done"}
+	if !reflect.DeepEqual(categories, wantKeys) {
+		t.Errorf("StatusCategoryKeys = %v, want %v", categories, wantKeys)
+	}
+}
+
+func TestStatusIsDone(t *testing.T) {
+	tests := []struct {
+		name   string
+		status *Status
+		want   bool
+	}{
+		{"nil status", nil, false},
+		{"no category", &Status{Name: "Closed"}, false},
+		{"to do", &Status{StatusCategory: &StatusCategory{Key: StatusCategoryToDo}}, false},
+		{"in progress", &Status{StatusCategory: &StatusCategory{Key: StatusCategoryInProgress}}, false},
+		{"done", &Status{StatusCategory: &StatusCategory{Key: StatusCategoryDone}}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.status.IsDone(); got != tt.want {
+			t.Errorf("%s: IsDone = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
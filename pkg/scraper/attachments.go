@@ -0,0 +1,136 @@
+package scraper
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/jctanner/go-jira-scraper/pkg/cache"
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// ArchiveResult summarizes an ArchiveAllAttachments run
+type ArchiveResult struct {
+	Total      int // Attachments found on cached issues
+	Downloaded int
+	Skipped    int // Already on disk with the expected size
+	Errors     int
+
+	// Failed maps the ID of each attachment that could not be downloaded
+	// to its error
+	Failed map[string]string
+}
+
+// attachmentJob is one attachment to archive
+type attachmentJob struct {
+	attachment models.Attachment
+	path       string
+}
+
+// ArchiveAllAttachments downloads the attachments of every cached issue
+// into the cache's attachment store with concurrency workers (default:
+// Config.Workers). Files already present with the expected size are
+// skipped, and partial files from an interrupted run are resumed, so the
+// archive can be rerun until complete. Issues must have been scraped with
+// the attachment field. progress, if set, is called after each attachment.
+func (s *Scraper) ArchiveAllAttachments(concurrency int, progress func(done, total int)) (*ArchiveResult, error) {
+	store, ok := s.cache.(cache.AttachmentStore)
+	if !ok {
+		return nil, fmt.Errorf("cache does not support storing attachments")
+	}
+	if concurrency <= 0 {
+		concurrency = s.config.Workers
+	}
+
+	keys, err := s.cache.ListIssues()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ArchiveResult{}
+	var jobs []attachmentJob
+	for _, key := range keys {
+		cached, err := s.cache.GetIssue(key)
+		if err != nil || cached.JiraData == nil || cached.JiraData.Key != key || cached.JiraData.Fields == nil {
+			// Skip unreadable records and alias links (e.g. moved issues)
+			continue
+		}
+		for _, attachment := range cached.JiraData.Fields.Attachments {
+			path := store.AttachmentPath(cached.JiraData, attachment)
+			if info, err := os.Stat(path); err == nil && info.Size() == attachment.Size {
+				result.Skipped++
+				continue
+			}
+			jobs = append(jobs, attachmentJob{attachment: attachment, path: path})
+		}
+	}
+	result.Total = result.Skipped + len(jobs)
+	s.logf(slog.LevelInfo, "Archiving %d attachments (%d already downloaded)", len(jobs), result.Skipped)
+
+	if err := s.running.start(); err != nil {
+		return nil, err
+	}
+	defer s.running.done()
+
+	queue := make(chan attachmentJob)
+	go func() {
+		defer close(queue)
+		for _, job := range jobs {
+			select {
+			case queue <- job:
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	type outcome struct {
+		id  string
+		err error
+	}
+	outcomes := make(chan outcome)
+	var workers sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range queue {
+				err := os.MkdirAll(filepath.Dir(job.path), 0755)
+				if err == nil {
+					err = s.client.DownloadAttachment(job.attachment, job.path)
+				}
+				outcomes <- outcome{id: job.attachment.ID, err: err}
+			}
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(outcomes)
+	}()
+
+	done := result.Skipped
+	for o := range outcomes {
+		done++
+		if o.err != nil {
+			s.logf(slog.LevelError, "Error archiving attachment %s: %v", o.id, o.err)
+			result.Errors++
+			if result.Failed == nil {
+				result.Failed = map[string]string{}
+			}
+			result.Failed[o.id] = o.err.Error()
+		} else {
+			result.Downloaded++
+		}
+		if progress != nil {
+			progress(done, result.Total)
+		}
+	}
+
+	if err := s.ctx.Err(); err != nil && done < result.Total {
+		return result, fmt.Errorf("archive cancelled after %d/%d attachments: %w", done, result.Total, err)
+	}
+	s.logf(slog.LevelInfo, "Archive complete: %d downloaded, %d skipped, %d errors", result.Downloaded, result.Skipped, result.Errors)
+	return result, nil
+}
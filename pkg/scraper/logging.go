@@ -0,0 +1,19 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+)
+
+// logf logs a scrape event through the client's logger (see
+// jira.Client.SetLogger), or as plain text via the log package without one
+func (s *Scraper) logf(level slog.Level, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if s.client == nil || s.client.Logger() == nil {
+		log.Print(msg)
+		return
+	}
+	s.client.Logger().Log(context.Background(), level, msg)
+}
@@ -0,0 +1,221 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jctanner/go-jira-scraper/pkg/jira"
+)
+
+// ErrClosed is returned by scrapes started after the scraper was closed
+var ErrClosed = errors.New("scraper is closed")
+
+// runTracker counts the scrapes whose writer is still draining results,
+// and refuses new ones once the scraper is closed
+type runTracker struct {
+	mu      sync.Mutex
+	idle    sync.Cond // Signalled when running drops to zero
+	running int
+	closed  bool
+}
+
+func newRunTracker() *runTracker {
+	t := &runTracker{}
+	t.idle.L = &t.mu
+	return t
+}
+
+// start registers a scrape, or returns ErrClosed after close
+func (t *runTracker) start() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return ErrClosed
+	}
+	t.running++
+	return nil
+}
+
+// done unregisters a scrape registered by start
+func (t *runTracker) done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.running--
+	if t.running == 0 {
+		t.idle.Broadcast()
+	}
+}
+
+// wait blocks until no scrapes are running; with close set, no new ones
+// can start afterwards
+func (t *runTracker) wait(close bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = t.closed || close
+	for t.running > 0 {
+		t.idle.Wait()
+	}
+}
+
+// fetchOutcome is a single worker result handed to the writer
+type fetchOutcome struct {
+	key     string
+	fetched *fetchedIssue
+	err     error
+}
+
+// fetchAndStore fetches keys with Config.Workers concurrent workers and
+// writes the results to the cache from a single writer (the calling
+// goroutine). When the scraper is closed, workers stop taking new keys but
+// every issue already fetched is still written before returning.
+//
+// Keys in verify are already cached; they are only fetched in full if a
+// freshness check shows the live issue changed, and otherwise count as
+// cache hits.
+//
+// With Config.FailFast, the first failure stops workers from taking new
+// keys and is returned once the issues in flight have been written.
+func (s *Scraper) fetchAndStore(keys []string, verify map[string]bool, result *ScrapeResult) error {
+	if err := s.running.start(); err != nil {
+		return err
+	}
+	defer s.running.done()
+
+	ctx, abort := context.WithCancel(s.ctx)
+	defer abort()
+	var failure error
+	failed := func(key string, err error) {
+		result.fail(key, err)
+		if s.config.FailFast && failure == nil {
+			failure = fmt.Errorf("scrape aborted at %s: %w", key, err)
+			abort()
+		}
+	}
+
+	jobs := make(chan string)
+	// Bounded so workers stall rather than buffer when the writer is slow
+	results := make(chan fetchOutcome, s.config.ResultBuffer)
+
+	// Feed keys until done or cancelled
+	go func() {
+		defer close(jobs)
+		for _, key := range keys {
+			select {
+			case jobs <- key:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var workers sync.WaitGroup
+	for i := 0; i < s.config.Workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for key := range jobs {
+				var fetched *fetchedIssue
+				var err error
+				fresh := false
+				if verify[key] {
+					fresh, err = s.isFresh(key)
+					if fresh {
+						err = jira.ErrNotModified
+					}
+				}
+				if err == nil && !fresh {
+					fetched, err = s.fetchIssue(key)
+				}
+				results <- fetchOutcome{key: key, fetched: fetched, err: err}
+
+				// Delay to avoid hitting rate limits (be polite to the API)
+				if delay := s.workerDelay(); delay > 0 && err == nil {
+					select {
+					case <-time.After(delay):
+					case <-ctx.Done():
+					}
+				}
+			}
+		}()
+	}
+
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	// Writer: drain every result, including those in flight at cancellation
+	done := 0
+	for outcome := range results {
+		done++
+		s.logf(slog.LevelInfo, "Fetched %d/%d: %s", done, len(keys), outcome.key)
+
+		if errors.Is(outcome.err, jira.ErrNotModified) {
+			// Conditional request confirmed the cached copy is current
+			result.APICalls++
+			result.CacheHits++
+			s.touchIssue(outcome.key)
+			continue
+		}
+		if jira.IsPermissionDenied(outcome.err) {
+			s.logf(slog.LevelWarn, "Skipping %s: permission denied (restricted issue)", outcome.key)
+			result.APICalls++
+			result.Restricted = append(result.Restricted, outcome.key)
+			continue
+		}
+		if outcome.err != nil {
+			s.logf(slog.LevelError, "Error fetching %s: %v", outcome.key, outcome.err)
+			failed(outcome.key, outcome.err)
+			continue
+		}
+		result.APICalls++
+
+		// Store in cache
+		changed, err := s.storeIssue(outcome.fetched)
+		if err != nil {
+			s.logf(slog.LevelError, "Error caching %s: %v", outcome.key, err)
+			failed(outcome.key, err)
+			continue
+		}
+		if changed {
+			result.Changed++
+		}
+	}
+
+	if failure != nil {
+		return failure
+	}
+	if err := s.ctx.Err(); err != nil && done < len(keys) {
+		return fmt.Errorf("scrape cancelled after %d/%d issues: %w", done, len(keys), err)
+	}
+	return nil
+}
+
+// fail records a key that could not be fetched or cached
+func (r *ScrapeResult) fail(key string, err error) {
+	r.Errors++
+	if r.Failed == nil {
+		r.Failed = map[string]string{}
+	}
+	r.Failed[key] = err.Error()
+}
+
+// Flush blocks until all running scrapes have written every issue they
+// fetched
+func (s *Scraper) Flush() {
+	s.running.wait(false)
+}
+
+// Close stops running scrapes from fetching further issues, waits for the
+// issues already fetched to be written to the cache, and returns. The
+// scraper cannot be used for new scrapes afterwards; they fail with
+// ErrClosed.
+func (s *Scraper) Close() error {
+	s.cancel()
+	s.running.wait(true)
+	return nil
+}
@@ -0,0 +1,164 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jctanner/go-jira-scraper/pkg/jira"
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// issueDoer answers every issue request with a minimal issue for the
+// requested key, counting the fetches
+type issueDoer struct {
+	fetched atomic.Int64
+}
+
+func (d *issueDoer) Do(req *http.Request) (*http.Response, error) {
+	key := path.Base(req.URL.Path)
+	body := fmt.Sprintf(`{"id":"%s","key":"%s","fields":{"summary":"issue %s"}}`,
+		strings.TrimPrefix(key, "P-"), key, key)
+	d.fetched.Add(1)
+	return &http.Response{
+		StatusCode:    200,
+		Header:        http.Header{},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// slowCache is an in-memory cache whose writes are slow. It records the
+// largest number of issues fetched but not yet taken by the writer.
+type slowCache struct {
+	doer    *issueDoer
+	delay   time.Duration
+	started atomic.Int64
+	peak    atomic.Int64
+
+	mu     sync.Mutex
+	issues map[string]*models.CachedIssue
+}
+
+func (c *slowCache) WriteIssue(issue *models.IssueWithHistory, duration time.Duration) (string, error) {
+	return c.WriteIssueWithMetadata(issue, models.CacheMetadata{})
+}
+
+func (c *slowCache) WriteIssueWithMetadata(issue *models.IssueWithHistory, meta models.CacheMetadata) (string, error) {
+	started := c.started.Add(1)
+	// Issues fetched beyond those the writer has taken are held in the
+	// result buffer or by blocked workers
+	if inFlight := c.doer.fetched.Load() - started; inFlight > c.peak.Load() {
+		c.peak.Store(inFlight)
+	}
+	time.Sleep(c.delay)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.issues[issue.Key] = &models.CachedIssue{CacheMetadata: meta, JiraData: issue}
+	return issue.Key, nil
+}
+
+func (c *slowCache) GetIssue(key string) (*models.CachedIssue, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.issues[key]
+	if !ok {
+		return nil, fmt.Errorf("issue not found in cache")
+	}
+	return cached, nil
+}
+
+func (c *slowCache) Exists(key string) bool {
+	_, err := c.GetIssue(key)
+	return err == nil
+}
+
+func (c *slowCache) ListIssues() ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]string, 0, len(c.issues))
+	for key := range c.issues {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (c *slowCache) GetLastFetched(key string) (time.Time, error) {
+	return time.Time{}, fmt.Errorf("not supported")
+}
+
+func TestFetchAndStoreBoundsInFlightResults(t *testing.T) {
+	doer := &issueDoer{}
+	client := jira.New("https://jira.example.com", "token")
+	client.SetDoer(doer)
+	client.SetRequestDelay(0)
+
+	store := &slowCache{doer: doer, delay: 2 * time.Millisecond, issues: map[string]*models.CachedIssue{}}
+	const workers, buffer = 4, 2
+	s := New(client, store, Config{Workers: workers, ResultBuffer: buffer})
+	defer s.Close()
+
+	keys := make([]string, 60)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("P-%d", i+1)
+	}
+
+	result, err := s.ScrapeKeys(keys)
+	if err != nil {
+		t.Fatalf("ScrapeKeys: %v", err)
+	}
+	if result.Errors != 0 || result.APICalls != len(keys) {
+		t.Errorf("result = %+v, want %d API calls and no errors", result, len(keys))
+	}
+	if n, _ := store.ListIssues(); len(n) != len(keys) {
+		t.Errorf("cached %d issues, want %d", len(n), len(keys))
+	}
+
+	t.Logf("peak in-flight results: %d", store.peak.Load())
+	if peak := store.peak.Load(); peak > buffer+workers {
+		t.Errorf("peak in-flight results = %d, want at most ResultBuffer+Workers = %d", peak, buffer+workers)
+	}
+	if peak := store.peak.Load(); peak == 0 {
+		t.Errorf("writer never fell behind; the test does not exercise backpressure")
+	}
+}
+
+func TestFlushRacingNewScrapes(t *testing.T) {
+	client := jira.New("https://jira.example.com", "token")
+	client.SetDoer(&issueDoer{})
+	client.SetRequestDelay(0)
+	s := New(client, newDiskCache(t), Config{Workers: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if _, err := s.ScrapeKeys([]string{fmt.Sprintf("P-%d", i*10+j+1)}); err != nil {
+					t.Errorf("ScrapeKeys: %v", err)
+				}
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		s.Flush()
+	}
+	wg.Wait()
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := s.ScrapeKeys([]string{"P-99"}); !errors.Is(err, ErrClosed) {
+		t.Errorf("ScrapeKeys after Close = %v, want ErrClosed", err)
+	}
+}
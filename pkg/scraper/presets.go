@@ -0,0 +1,93 @@
+package scraper
+
+import (
+	"fmt"
+
+	"github.com/jctanner/go-jira-scraper/pkg/jira"
+)
+
+// FieldPreset is a named bundle of fields and expansions requested per issue
+type FieldPreset struct {
+	Fields []string // Fields to request; empty means all fields
+	Expand []string // Expansions to request, e.g. "changelog"
+}
+
+// FieldPresets are the built-in scrape profiles selectable via Config.Preset
+var FieldPresets = map[string]FieldPreset{
+	// full: every field plus the complete changelog (the default)
+	"full": {
+		Expand: []string{"changelog"},
+	},
+	// snapshot: every field, current state only
+	"snapshot": {},
+	// triage: the fields needed to triage a backlog, with history
+	"triage": {
+		Fields: []string{"summary", "issuetype", "status", "priority", "assignee", "labels", "components", "created", "updated"},
+		Expand: []string{"changelog"},
+	},
+	// release: version and resolution tracking for release reports
+	"release": {
+		Fields: []string{"summary", "issuetype", "status", "resolution", "resolutiondate", "fixVersions", "versions", "created", "updated"},
+	},
+}
+
+// fetchOptions translates the configured preset, field list and
+// FetchHistory setting into per-issue request options. An explicit Fields
+// list replaces the preset's fields; an explicit FetchHistory overrides
+// whether the changelog is expanded.
+func (c Config) fetchOptions() (jira.FetchOptions, error) {
+	name := c.Preset
+	if name == "" {
+		name = "full"
+	}
+	preset, ok := FieldPresets[name]
+	if !ok {
+		return jira.FetchOptions{}, fmt.Errorf("unknown field preset %q", c.Preset)
+	}
+
+	opts := jira.FetchOptions{
+		Fields: preset.Fields,
+	}
+	if len(c.Fields) > 0 {
+		opts.Fields = c.Fields
+	}
+
+	history := false
+	for _, expand := range preset.Expand {
+		if expand == "changelog" {
+			history = true
+			continue
+		}
+		opts.Expand = append(opts.Expand, expand)
+	}
+	if c.FetchHistory != nil {
+		history = *c.FetchHistory
+	}
+	if history {
+		opts.Expand = append(opts.Expand, "changelog")
+	}
+	if c.FollowLinks && len(opts.Fields) > 0 {
+		// Copy so the preset's list is not modified
+		opts.Fields = append([]string(nil), opts.Fields...)
+		for _, field := range []string{"issuelinks", "subtasks", "parent"} {
+			if !containsString(opts.Fields, field) {
+				opts.Fields = append(opts.Fields, field)
+			}
+		}
+	}
+	if c.FetchRendered && !containsString(opts.Expand, "renderedFields") {
+		opts.Expand = append(opts.Expand, "renderedFields")
+	}
+
+	return opts, nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
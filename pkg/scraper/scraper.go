@@ -1,27 +1,135 @@
 package scraper
 
 import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/jctanner/go-jira-scraper/pkg/cache"
+	"github.com/jctanner/go-jira-scraper/pkg/history"
 	"github.com/jctanner/go-jira-scraper/pkg/jira"
+	"github.com/jctanner/go-jira-scraper/pkg/jql"
+	"github.com/jctanner/go-jira-scraper/pkg/models"
 )
 
 // Scraper orchestrates the scraping process
 type Scraper struct {
 	client *jira.Client
-	cache  *cache.DiskCache
+	cache  cache.Cache
 	config Config
+
+	// override adjusts a per-project copy made by forProject
+	override ProjectOverride
+
+	// ctx is cancelled by Close to stop workers picking up new issues;
+	// running tracks scrapes whose writer is still draining results. Both
+	// are shared with per-project copies.
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running *runTracker
 }
 
+// Ensure DiskCache can checkpoint client discovery searches
+var _ jira.DiscoveryCheckpoint = (*cache.DiskCache)(nil)
+
 // Config holds scraper configuration
 type Config struct {
 	Workers   int
 	FullSync  bool
 	BatchSize int
 	Limit     int
+	OrderBy   string // JQL ORDER BY clause for discovery, e.g. "created ASC" (default: "updated DESC")
+
+	// ResultBuffer is the number of fetched issues that may wait for the
+	// cache writer (default: Workers). When it is full, workers block
+	// until the writer catches up, bounding memory on slow storage.
+	ResultBuffer int
+
+	// SearchCacheTTL enables reuse of discovery results from a previous run
+	// within this window, when the cache supports it. Zero disables it.
+	SearchCacheTTL time.Duration
+
+	// OnIssueCached is called after an issue is written to the cache. It
+	// runs synchronously; a panicking hook is logged and does not abort the scrape.
+	OnIssueCached func(ctx context.Context, cached *models.CachedIssue)
+
+	// FetchHistory controls whether the changelog is fetched with each issue.
+	// nil defers to the preset (which fetches it by default); set to false
+	// for fast current-state snapshots.
+	FetchHistory *bool
+
+	// CheckFreshness makes incremental scrapes re-fetch cached issues whose
+	// live updated time (checked with a tiny per-issue request) is newer
+	// than the cached copy, instead of trusting that cached means current.
+	CheckFreshness bool
+
+	// Preset selects a named bundle of fields/expansions from FieldPresets
+	// (default: "full"). Fields, if set, replaces the preset's field list.
+	Preset string
+	Fields []string
+
+	// FetchRemoteLinks stores each issue's remote links (PRs, wiki pages)
+	// in its cache metadata, at the cost of one extra request per fetched
+	// issue
+	FetchRemoteLinks bool
+
+	// FetchTransitions stores the workflow transitions available on each
+	// issue when it was fetched in its cache metadata, at the cost of one
+	// extra request per issue
+	FetchTransitions bool
+
+	// ResumeDiscovery saves search progress to the cache while discovering
+	// issues, so an interrupted discovery resumes from its last saved page
+	// (when the cache supports it)
+	ResumeDiscovery bool
+
+	// ProjectOverrides adjusts settings per project in ScrapeProjects
+	ProjectOverrides map[string]ProjectOverride
+
+	// FetchRendered also requests JIRA's server-rendered HTML of the
+	// description and comments (expand=renderedFields)
+	FetchRendered bool
+
+	// FollowLinks also fetches the issues linked to scraped issues (issue
+	// links, subtasks and parents), up to LinkDepth hops away (default 1),
+	// stopping after MaxLinkedIssues linked issues (default 1000)
+	FollowLinks     bool
+	LinkDepth       int
+	MaxLinkedIssues int
+
+	// Since restricts project and JQL scrapes to issues updated within this
+	// window (e.g. 24h for a daily job), using JIRA's relative date syntax.
+	// Such scrapes bypass the recorded watermark and do not advance it.
+	Since time.Duration
+
+	// FailFast aborts a scrape at the first issue that fails to fetch or
+	// cache, returning the error with the partial ScrapeResult. By default
+	// failures are counted and the scrape continues.
+	FailFast bool
+
+	// Transforms are applied in order to each issue record before it is
+	// cached (see RedactTransform and StripFieldsTransform). Raw responses
+	// are not stored while transforms are set, as they bypass them; New
+	// logs a warning if the cache was set to store them.
+	Transforms []Transform
+}
+
+// ProjectOverride adjusts how one project is scraped by ScrapeProjects.
+// A set (non-zero) override takes precedence over Config for that project
+// only; zero values fall back to Config, and for RequestDelay to the
+// client's delay. Searches keep the client's own pacing.
+type ProjectOverride struct {
+	Workers      int           // Concurrent fetch workers
+	RequestDelay time.Duration // Delay between issue fetches per worker; negative: none
 }
 
 // ScrapeResult contains the results of a scrape operation
@@ -29,137 +137,1034 @@ type ScrapeResult struct {
 	IssuesProcessed int
 	APICalls        int
 	CacheHits       int
+	Changed         int
 	Errors          int
 	Duration        time.Duration
+
+	// Failed maps each key that could not be fetched or cached to its error
+	Failed map[string]string
+
+	// Restricted lists the keys JIRA refused to return (403), typically
+	// because of issue security levels. They are not counted as errors.
+	Restricted []string
 }
 
 // New creates a new Scraper instance
-func New(client *jira.Client, cache *cache.DiskCache, config Config) *Scraper {
+func New(client *jira.Client, cache cache.Cache, config Config) *Scraper {
 	// Set defaults
+	if config.OrderBy == "" {
+		config.OrderBy = jira.DefaultOrderBy
+	}
 	if config.Workers == 0 {
 		config.Workers = 4
 	}
 	if config.BatchSize == 0 {
 		config.BatchSize = 100
 	}
+	if config.ResultBuffer <= 0 {
+		config.ResultBuffer = config.Workers
+	}
+	if config.LinkDepth <= 0 {
+		config.LinkDepth = 1
+	}
+	if config.MaxLinkedIssues <= 0 {
+		config.MaxLinkedIssues = 1000
+	}
+
+	if checkpoint, ok := cache.(jira.DiscoveryCheckpoint); ok && config.ResumeDiscovery {
+		client.SetDiscoveryCheckpoint(checkpoint, 0)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := &Scraper{
+		client:  client,
+		cache:   cache,
+		config:  config,
+		ctx:     ctx,
+		cancel:  cancel,
+		running: newRunTracker(),
+	}
+	s.warnRawSkipped()
+	return s
+}
 
-	return &Scraper{
-		client: client,
-		cache:  cache,
-		config: config,
+// warnRawSkipped logs when a cache set to keep raw responses will not,
+// because transforms are configured and raw responses would bypass them
+func (s *Scraper) warnRawSkipped() {
+	if raw, ok := s.cache.(cache.RawStore); ok && raw.StoresRaw() && len(s.config.Transforms) > 0 {
+		s.logf(slog.LevelWarn, "Warning: raw responses are not stored while transforms are configured")
 	}
 }
 
-// ScrapeProject fetches all issues from a project
+// ScrapeProjects scrapes several projects in turn, applying any
+// Config.ProjectOverrides to each. A failing project does not stop the
+// others; the results of every attempted project are returned along with
+// the joined errors.
+func (s *Scraper) ScrapeProjects(projects []string) (map[string]*ScrapeResult, error) {
+	results := make(map[string]*ScrapeResult, len(projects))
+	var errs []error
+
+	for _, project := range projects {
+		if s.ctx.Err() != nil {
+			errs = append(errs, s.ctx.Err())
+			break
+		}
+
+		result, err := s.forProject(project).ScrapeProject(project)
+
+		if result != nil {
+			results[project] = result
+		}
+		if err != nil {
+			s.logf(slog.LevelError, "Scrape of project %s failed: %v", project, err)
+			errs = append(errs, fmt.Errorf("project %s: %w", project, err))
+			if s.config.FailFast {
+				break
+			}
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// forProject returns a scraper applying the project's override, sharing
+// the client, cache and lifecycle with s, or s itself if there is none.
+// The override never changes s or the client, which other scrapes may be
+// using concurrently.
+func (s *Scraper) forProject(project string) *Scraper {
+	override, ok := s.config.ProjectOverrides[project]
+	if !ok {
+		return s
+	}
+
+	run := &Scraper{
+		client:   s.client,
+		cache:    s.cache,
+		config:   s.config,
+		override: override,
+		ctx:      s.ctx,
+		cancel:   s.cancel,
+		running:  s.running,
+	}
+	if override.Workers > 0 {
+		run.config.Workers = override.Workers
+	}
+	s.logf(slog.LevelInfo, "Using overrides for project %s: %d workers, %v request delay", project, run.config.Workers, run.workerDelay())
+	return run
+}
+
+// workerDelay returns the pause each fetch worker takes after an issue:
+// the override's delay if set, otherwise the client's
+func (s *Scraper) workerDelay() time.Duration {
+	switch {
+	case s.override.RequestDelay < 0:
+		return 0
+	case s.override.RequestDelay > 0:
+		return s.override.RequestDelay
+	}
+	return s.client.RequestDelay()
+}
+
+// watermarkOverlap widens each incremental window to absorb clock skew
+// between this host and JIRA and JQL's minute precision
+const watermarkOverlap = 5 * time.Minute
+
+// ScrapeProject fetches all issues from a project. Incremental scrapes of a
+// project with a recorded watermark only discover and re-fetch issues
+// updated since the previous scrape.
 func (s *Scraper) ScrapeProject(project string) (*ScrapeResult, error) {
 	start := time.Now()
-	result := &ScrapeResult{}
 
-	log.Printf("Starting scrape of project: %s", project)
+	s.logf(slog.LevelInfo, "Starting scrape of project: %s", project)
+
+	if s.config.Since != 0 {
+		return s.scrapeWindow(project, jql.Project(project), s.config.OrderBy, start)
+	}
+	if watermark := s.lastWatermark(project); !watermark.IsZero() {
+		return s.scrapeSince(project, jql.Project(project), watermark, start)
+	}
 
 	// Get all issue keys from JIRA
-	log.Printf("Searching for issues in project %s...", project)
-	issueKeys, err := s.client.GetAllIssuesInProject(project, "updated DESC", s.config.Limit)
+	s.logf(slog.LevelInfo, "Searching for issues in project %s...", project)
+	query, err := jira.ProjectJQL(project, s.config.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+	issueKeys, err := s.discover(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search issues: %w", err)
+	}
+
+	s.logf(slog.LevelInfo, "Found %d issues in project %s", len(issueKeys), project)
+	result, err := s.scrapeIssueKeys(issueKeys, start, s.config.FullSync)
+	if err != nil {
+		return result, err
+	}
+
+	s.recordSync(project, result, s.discoveryWatermark(start))
+	return result, nil
+}
+
+// lastWatermark returns the recorded update watermark of a sync state for
+// incremental scrapes, or zero when the scrape must discover everything
+func (s *Scraper) lastWatermark(name string) time.Time {
+	store, ok := s.cache.(cache.SyncStateStore)
+	if !ok || s.config.FullSync {
+		return time.Time{}
+	}
+	state, err := store.LastSync(name)
+	if err != nil {
+		s.logf(slog.LevelWarn, "Warning: failed to read sync state for %s: %v", name, err)
+		return time.Time{}
+	}
+	return state.UpdatedWatermark
+}
+
+// discoveryWatermark returns the watermark earned by a scrape that started
+// at start: a complete discovery saw every issue updated before it began
+func (s *Scraper) discoveryWatermark(start time.Time) time.Time {
+	if s.config.Limit > 0 {
+		return time.Time{}
+	}
+	return start.UTC()
+}
+
+// scrapeSince re-fetches the issues matching where that were updated since
+// the watermark, oldest update first, and advances the watermark of the
+// named sync state to the newest update seen. The window is expressed
+// relative to now (e.g. updated >= -95m) so it does not depend on the JIRA
+// user's time zone.
+func (s *Scraper) scrapeSince(name string, where jql.Clause, watermark time.Time, start time.Time) (*ScrapeResult, error) {
+	query, err := jql.New(
+		where,
+		jql.UpdatedWithin(time.Since(watermark)+watermarkOverlap),
+	).OrderBy(jql.OrderBy("updated", jql.Asc)).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	s.logf(slog.LevelInfo, "Searching for issues updated since %s: %s", watermark.Format(time.RFC3339), query)
+	issues, err := s.client.SearchAll(query, s.config.Limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search issues: %w", err)
 	}
 
-	log.Printf("Found %d issues in project %s", len(issueKeys), project)
+	issueKeys := make([]string, 0, len(issues))
+	next := watermark
+	for _, issue := range issues {
+		issueKeys = append(issueKeys, issue.Key)
+		if issue.Fields == nil {
+			continue
+		}
+		if updated, err := models.ParseTime(issue.Fields.Updated); err == nil && updated.After(next) {
+			next = updated.UTC()
+		}
+	}
+
+	s.logf(slog.LevelInfo, "Found %d updated issues", len(issueKeys))
+
+	// Cached copies of these issues are stale by definition
+	result, err := s.scrapeIssueKeys(issueKeys, start, true)
+	if err != nil {
+		return result, err
+	}
+
+	s.recordSync(name, result, next)
+	return result, nil
+}
+
+// scrapeWindow fetches the issues matching where that were updated within
+// Config.Since. The window is a partial discovery, so the named sync state
+// (if any) is recorded without a new watermark.
+func (s *Scraper) scrapeWindow(name string, where jql.Clause, orderBy string, start time.Time) (*ScrapeResult, error) {
+	if s.config.Since < time.Minute {
+		return nil, fmt.Errorf("since window must be at least a minute, got %v", s.config.Since)
+	}
+
+	query, err := jql.New(where, jql.UpdatedWithin(s.config.Since)).OrderByClause(orderBy).Build()
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+
+	s.logf(slog.LevelInfo, "Searching for issues updated in the last %v: %s", s.config.Since, query)
+	issueKeys, err := s.discover(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search issues: %w", err)
+	}
+
+	s.logf(slog.LevelInfo, "Found %d updated issues", len(issueKeys))
+
+	// Cached copies of these issues may predate the update
+	result, err := s.scrapeIssueKeys(issueKeys, start, true)
+	if err != nil {
+		return result, err
+	}
+
+	if name != "" {
+		s.recordSync(name, result, time.Time{})
+	}
+	return result, nil
+}
+
+// recordSync updates the project's sync state with a scrape's counts. The
+// sync time and a non-zero watermark are only recorded for a scrape without
+// errors, so they always reflect a complete sync.
+func (s *Scraper) recordSync(project string, result *ScrapeResult, watermark time.Time) {
+	store, ok := s.cache.(cache.SyncStateStore)
+	if !ok {
+		return
+	}
+
+	state, err := store.LastSync(project)
+	if err != nil {
+		s.logf(slog.LevelWarn, "Warning: failed to read sync state for %s: %v", project, err)
+		state = &cache.SyncState{Project: project}
+	}
+
+	state.IssuesProcessed = result.IssuesProcessed
+	state.IssuesFetched = result.APICalls
+	state.CacheHits = result.CacheHits
+	state.Errors = result.Errors
+	if result.Errors == 0 {
+		now := time.Now().UTC()
+		if s.config.FullSync && s.config.Limit == 0 {
+			state.LastFullSync = now
+		} else {
+			state.LastIncrementalSync = now
+		}
+		if !watermark.IsZero() {
+			state.UpdatedWatermark = watermark
+		}
+	}
+
+	if err := store.WriteSyncState(state); err != nil {
+		s.logf(slog.LevelWarn, "Warning: failed to write sync state for %s: %v", project, err)
+	}
+}
+
+// ScrapeJQL fetches all issues matching a JQL query. Like project scrapes,
+// incremental scrapes of a previously completed query only re-fetch issues
+// updated since, tracked by a watermark keyed by a hash of the query's
+// condition. Such scrapes order by updated ASC in place of any ORDER BY in
+// the query.
+func (s *Scraper) ScrapeJQL(query string) (*ScrapeResult, error) {
+	start := time.Now()
+
+	where, orderBy := jql.SplitOrderBy(query)
+	if orderBy != "" {
+		if err := jql.ValidateOrderBy(orderBy); err != nil {
+			return nil, fmt.Errorf("invalid ORDER BY in query: %w", err)
+		}
+	}
+	name := jqlSyncName(where)
+
+	s.logf(slog.LevelInfo, "Starting scrape of query: %s", query)
+	if s.config.Since != 0 {
+		if where == "" {
+			return s.scrapeWindow("", nil, orderBy, start)
+		}
+		return s.scrapeWindow(name, jql.Raw(where), orderBy, start)
+	}
+	if where != "" {
+		if watermark := s.lastWatermark(name); !watermark.IsZero() {
+			return s.scrapeSince(name, jql.Raw(where), watermark, start)
+		}
+	}
+
+	issueKeys, err := s.discover(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search issues: %w", err)
+	}
+
+	s.logf(slog.LevelInfo, "Found %d issues matching query", len(issueKeys))
+	result, err := s.scrapeIssueKeys(issueKeys, start, s.config.FullSync)
+	if err != nil {
+		return result, err
+	}
+
+	if where != "" {
+		s.recordSync(name, result, s.discoveryWatermark(start))
+	}
+	return result, nil
+}
+
+// ScrapeDateRange fetches the issues of a project last updated in [from,
+// to), for backfilling in chunks that separate runs can share out. JQL has
+// minute precision, so both bounds must be whole minutes, and reads them in
+// the JIRA user's time zone, so pass times in that zone. Cached issues are
+// skipped unless FullSync is set, and no sync state is recorded since the
+// range is a partial scrape.
+func (s *Scraper) ScrapeDateRange(project string, from, to time.Time) (*ScrapeResult, error) {
+	start := time.Now()
+
+	// Seconds would be dropped from the query, so chunks split within a
+	// minute would overlap or come out empty
+	for _, bound := range []time.Time{from, to} {
+		if bound.Second() != 0 || bound.Nanosecond() != 0 {
+			return nil, fmt.Errorf("invalid date range: %s is not a whole minute", bound.Format(time.RFC3339Nano))
+		}
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid date range: %s is not before %s", from.Format(jql.DateLayout), to.Format(jql.DateLayout))
+	}
+	query, err := jql.New(
+		jql.Project(project),
+		jql.UpdatedAfter(from),
+		jql.UpdatedBefore(to),
+	).OrderByClause(s.config.OrderBy).Build()
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+
+	s.logf(slog.LevelInfo, "Starting scrape of project %s updated from %s to %s", project, from.Format(jql.DateLayout), to.Format(jql.DateLayout))
+	issueKeys, err := s.discover(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search issues: %w", err)
+	}
+
+	s.logf(slog.LevelInfo, "Found %d issues in range", len(issueKeys))
+	return s.scrapeIssueKeys(issueKeys, start, s.config.FullSync)
+}
+
+// ScrapeKeys fetches exactly the given issues, e.g. a curated list read
+// with ReadKeys. Duplicates and blank keys are ignored; cached issues are
+// skipped unless FullSync is set. Keys that fail are listed in the
+// result's Failed map.
+func (s *Scraper) ScrapeKeys(keys []string) (*ScrapeResult, error) {
+	start := time.Now()
+
+	seen := make(map[string]bool, len(keys))
+	unique := make([]string, 0, len(keys))
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, key)
+	}
+
+	s.logf(slog.LevelInfo, "Starting scrape of %d listed issues", len(unique))
+	return s.scrapeIssueKeys(unique, start, s.config.FullSync)
+}
+
+// ReadKeys reads issue keys, one per line, for ScrapeKeys. Blank lines and
+// lines starting with # are skipped.
+func ReadKeys(r io.Reader) ([]string, error) {
+	var keys []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys = append(keys, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read keys: %w", err)
+	}
+	return keys, nil
+}
+
+// jqlSyncName returns the sync state name for a JQL condition
+func jqlSyncName(where string) string {
+	sum := sha256.Sum256([]byte(where))
+	return "jql-" + hex.EncodeToString(sum[:8])
+}
+
+// ScrapeFilter fetches all issues returned by a saved JIRA filter
+func (s *Scraper) ScrapeFilter(id string) (*ScrapeResult, error) {
+	filter, err := s.client.GetFilter(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logf(slog.LevelInfo, "Resolved filter %s (%s) to: %s", filter.ID, filter.Name, filter.JQL)
+	return s.ScrapeJQL(filter.JQL)
+}
+
+// scrapeIssueKeys fetches and caches the given discovered issue keys. With
+// refetch set, cached issues are fetched again rather than counted as hits.
+func (s *Scraper) scrapeIssueKeys(issueKeys []string, start time.Time, refetch bool) (*ScrapeResult, error) {
+	if _, err := s.config.fetchOptions(); err != nil {
+		return nil, err
+	}
+
+	result := &ScrapeResult{}
 	result.IssuesProcessed = len(issueKeys)
 
 	// Determine which issues need fetching
+	exists := s.cache.Exists
+	if batch, ok := s.cache.(cache.BatchChecker); ok && !refetch {
+		cached := batch.ExistsBatch(issueKeys)
+		exists = func(key string) bool { return cached[key] }
+	}
+
 	toFetch := []string{}
+	verify := map[string]bool{}
 	for _, key := range issueKeys {
-		if s.config.FullSync {
+		if refetch {
 			// Full sync: fetch everything
 			toFetch = append(toFetch, key)
 		} else {
 			// Incremental: only fetch if not in cache or outdated
-			if !s.cache.Exists(key) {
+			if !exists(key) {
 				toFetch = append(toFetch, key)
+			} else if s.config.CheckFreshness {
+				toFetch = append(toFetch, key)
+				verify[key] = true
 			} else {
 				result.CacheHits++
 			}
 		}
 	}
 
-	log.Printf("Need to fetch %d issues (%d cache hits)", len(toFetch), result.CacheHits)
+	s.logf(slog.LevelInfo, "Need to fetch %d issues (%d to verify, %d cache hits)", len(toFetch), len(verify), result.CacheHits)
 
-	// Fetch issues (for now, sequentially - we'll add concurrency later)
-	for i, key := range toFetch {
-		log.Printf("Fetching %d/%d: %s", i+1, len(toFetch), key)
-		
-		issue, duration, err := s.client.GetIssueWithHistory(key)
-		if err != nil {
-			log.Printf("Error fetching %s: %v", key, err)
-			result.Errors++
-			continue
+	// Fetch issues with the worker pool; every fetched issue is written
+	// even if the scrape is cancelled part way through
+	err := s.fetchAndStore(toFetch, verify, result)
+	if err == nil && s.config.FollowLinks {
+		err = s.followLinks(issueKeys, result)
+	}
+
+	result.Duration = time.Since(start)
+	if err != nil {
+		s.logf(slog.LevelWarn, "Scrape stopped: %d issues, %d API calls, %d cache hits, %d changed, %d restricted, %d errors in %s: %v",
+			result.IssuesProcessed, result.APICalls, result.CacheHits, result.Changed, len(result.Restricted), result.Errors, result.Duration, err)
+		return result, err
+	}
+	s.logf(slog.LevelInfo, "Scrape complete: %d issues, %d API calls, %d cache hits, %d changed, %d restricted, %d errors in %s",
+		result.IssuesProcessed, result.APICalls, result.CacheHits, result.Changed, len(result.Restricted), result.Errors, result.Duration)
+
+	return result, nil
+}
+
+// followLinks fetches the uncached issues linked to the given ones,
+// breadth first up to Config.LinkDepth hops. Issues already seen are not
+// revisited, so cycles terminate, and at most Config.MaxLinkedIssues
+// linked issues are added.
+func (s *Scraper) followLinks(keys []string, result *ScrapeResult) error {
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		seen[key] = true
+	}
+
+	added := 0
+	frontier := keys
+	for depth := 1; depth <= s.config.LinkDepth && len(frontier) > 0; depth++ {
+		var next, toFetch []string
+		full := false
+		for _, key := range frontier {
+			cached, err := s.cache.GetIssue(key)
+			if err != nil || cached.JiraData == nil {
+				continue
+			}
+			for _, linked := range cached.JiraData.Fields.LinkedKeys() {
+				if seen[linked] {
+					continue
+				}
+				if added >= s.config.MaxLinkedIssues {
+					full = true
+					break
+				}
+				seen[linked] = true
+				added++
+				next = append(next, linked)
+				if !s.cache.Exists(linked) {
+					toFetch = append(toFetch, linked)
+				}
+			}
 		}
-		result.APICalls++
 
-		// Store in cache
-		_, err = s.cache.WriteIssue(issue, duration)
-		if err != nil {
-			log.Printf("Error caching %s: %v", key, err)
-			result.Errors++
-			continue
+		s.logf(slog.LevelInfo, "Following links (depth %d): %d linked issues, %d to fetch", depth, len(next), len(toFetch))
+		result.IssuesProcessed += len(next)
+		result.CacheHits += len(next) - len(toFetch)
+		if err := s.fetchAndStore(toFetch, nil, result); err != nil {
+			return err
+		}
+		if full {
+			s.logf(slog.LevelWarn, "Warning: reached limit of %d linked issues, not following further links", s.config.MaxLinkedIssues)
+			return nil
 		}
+		frontier = next
+	}
+	return nil
+}
 
-		// Delay to avoid hitting rate limits (be polite to the API)
-		time.Sleep(500 * time.Millisecond)
+// discover runs a JQL search for issue keys, reusing a recent cached result
+// when SearchCacheTTL is set and the cache supports it
+func (s *Scraper) discover(jql string) ([]string, error) {
+	searchCache, ok := s.cache.(cache.SearchCache)
+	if !ok || s.config.SearchCacheTTL <= 0 {
+		return s.client.GetAllIssuesForJQL(jql, s.config.Limit)
 	}
 
-	result.Duration = time.Since(start)
-	log.Printf("Scrape complete: %d issues, %d API calls, %d cache hits, %d errors in %s",
-		result.IssuesProcessed, result.APICalls, result.CacheHits, result.Errors, result.Duration)
+	if keys, found := searchCache.GetSearchResult(jql, s.config.Limit, s.config.SearchCacheTTL); found {
+		s.logf(slog.LevelInfo, "Using cached search result (%d issues) for: %s", len(keys), jql)
+		return keys, nil
+	}
 
-	return result, nil
+	keys, err := s.client.GetAllIssuesForJQL(jql, s.config.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := searchCache.WriteSearchResult(jql, s.config.Limit, keys); err != nil {
+		s.logf(slog.LevelWarn, "Warning: failed to cache search result: %v", err)
+	}
+	return keys, nil
 }
 
 // ScrapeIssue fetches a single issue
 func (s *Scraper) ScrapeIssue(key string) error {
-	log.Printf("Fetching issue: %s", key)
+	s.logf(slog.LevelInfo, "Fetching issue: %s", key)
 
-	issue, duration, err := s.client.GetIssueWithHistory(key)
+	fetched, err := s.fetchIssue(key)
+	if errors.Is(err, jira.ErrNotModified) {
+		s.logf(slog.LevelInfo, "%s not modified since last fetch", key)
+		s.touchIssue(key)
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to fetch issue: %w", err)
 	}
 
-	_, err = s.cache.WriteIssue(issue, duration)
+	changed, err := s.storeIssue(fetched)
 	if err != nil {
 		return fmt.Errorf("failed to cache issue: %w", err)
 	}
+	if !changed {
+		s.logf(slog.LevelInfo, "%s unchanged since last fetch", key)
+		return nil
+	}
 
-	log.Printf("Successfully fetched and cached %s", key)
+	s.logf(slog.LevelInfo, "Successfully fetched and cached %s", key)
 	return nil
 }
 
-// ValidateCache checks cache integrity
-func (s *Scraper) ValidateCache() error {
-	log.Println("Validating cache...")
-	
-	keys, err := s.cache.ListIssues()
+// fetchIssue fetches an issue with the fields and expansions of the configured preset.
+// If the issue is already cached with an ETag or Last-Modified value, a
+// conditional request is made and jira.ErrNotModified is returned when unchanged.
+func (s *Scraper) fetchIssue(key string) (*fetchedIssue, error) {
+	opts, err := s.config.fetchOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	cached, cacheErr := s.cache.GetIssue(key)
+	if cacheErr == nil {
+		opts.ETag = cached.CacheMetadata.ETag
+		opts.LastModified = cached.CacheMetadata.LastModified
+	}
+
+	fetched, err := s.client.FetchIssue(key, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &fetchedIssue{FetchResult: fetched}
+	if s.config.FetchRemoteLinks {
+		links, err := s.client.GetRemoteLinks(fetched.Issue.Key)
+		if err != nil {
+			s.logf(slog.LevelWarn, "Warning: failed to fetch remote links for %s: %v", fetched.Issue.Key, err)
+		} else {
+			result.remoteLinks = links
+		}
+	}
+	if s.config.FetchTransitions {
+		transitions, err := s.client.GetAvailableTransitions(fetched.Issue.Key)
+		if err != nil {
+			s.logf(slog.LevelWarn, "Warning: failed to fetch transitions for %s: %v", fetched.Issue.Key, err)
+		} else {
+			result.transitions = transitions
+		}
+	}
+
+	if fetched.Moved() {
+		result.renamed = &models.RenameInfo{
+			FromKey:    fetched.RequestedKey,
+			ToKey:      fetched.Issue.Key,
+			DetectedAt: time.Now().UTC(),
+		}
+	} else if cacheErr == nil {
+		// Remember earlier moves so the old key keeps resolving
+		result.renamed = cached.CacheMetadata.Renamed
+	}
+	return result, nil
+}
+
+// isFresh reports whether the cached copy of key is at least as new as the
+// live issue, using a lightweight updated-time request
+func (s *Scraper) isFresh(key string) (bool, error) {
+	cached, err := s.cache.GetIssue(key)
+	if err != nil {
+		return false, nil
+	}
+
+	live, err := s.client.GetIssueUpdatedTime(key)
+	if err != nil {
+		return false, err
+	}
+	return isCurrent(cached, live), nil
+}
+
+// isCurrent reports whether a cached issue reflects the live updated time,
+// comparing against the cached updated field, or the fetch time if that is
+// missing
+func isCurrent(cached *models.CachedIssue, live time.Time) bool {
+	if cached.JiraData != nil && cached.JiraData.Fields != nil {
+		if updated, err := models.ParseTime(cached.JiraData.Fields.Updated); err == nil {
+			return !live.After(updated)
+		}
+	}
+	return !live.After(cached.CacheMetadata.FetchedAt)
+}
+
+// Coverage compares a project's cached issues against a fresh discovery
+// search, returning how many of its live issues are cached, the live
+// total, and the sorted keys of live issues missing from the cache (to
+// top up with ScrapeKeys). Cached issues no longer in JIRA are not counted.
+func (s *Scraper) Coverage(project string) (cached int, live int, missing []string, err error) {
+	query, err := jira.ProjectJQL(project, s.config.OrderBy)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	keys, err := s.client.GetAllIssuesForJQL(query, 0)
 	if err != nil {
-		return fmt.Errorf("failed to list cached issues: %w", err)
+		return 0, 0, nil, fmt.Errorf("failed to search issues: %w", err)
 	}
 
-	log.Printf("Found %d cached issues", len(keys))
-	
-	errors := 0
+	exists := s.cache.Exists
+	if batch, ok := s.cache.(cache.BatchChecker); ok {
+		found := batch.ExistsBatch(keys)
+		exists = func(key string) bool { return found[key] }
+	}
 	for _, key := range keys {
-		_, err := s.cache.GetIssue(key)
+		if exists(key) {
+			cached++
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+
+	s.logf(slog.LevelInfo, "Cache covers %d of %d issues in project %s (%d missing)", cached, len(keys), project, len(missing))
+	return cached, len(keys), missing, nil
+}
+
+// ScanStale compares a project's cached issues against JIRA without
+// fetching them, using only the discovery search, and returns the keys of
+// issues that changed since they were cached or are not cached at all
+func (s *Scraper) ScanStale(project string) ([]string, error) {
+	query, err := jira.ProjectJQL(project, s.config.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	issues, err := s.client.SearchAll(query, s.config.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search issues: %w", err)
+	}
+
+	var stale []string
+	for _, issue := range issues {
+		cached, err := s.cache.GetIssue(issue.Key)
 		if err != nil {
-			log.Printf("Error reading %s: %v", key, err)
-			errors++
+			stale = append(stale, issue.Key)
+			continue
+		}
+		if issue.Fields == nil {
+			continue
+		}
+		live, err := models.ParseTime(issue.Fields.Updated)
+		if err != nil {
+			s.logf(slog.LevelWarn, "Warning: cannot parse updated time %q of %s", issue.Fields.Updated, issue.Key)
+			continue
+		}
+		if !isCurrent(cached, live) {
+			stale = append(stale, issue.Key)
 		}
 	}
 
-	if errors > 0 {
-		log.Printf("Cache validation found %d errors", errors)
-	} else {
-		log.Println("Cache validation passed")
+	s.logf(slog.LevelInfo, "Found %d stale or missing issues of %d in project %s", len(stale), len(issues), project)
+	return stale, nil
+}
+
+// fetchedIssue is a fetch result plus scraper-side cache bookkeeping
+type fetchedIssue struct {
+	*jira.FetchResult
+	renamed     *models.RenameInfo
+	remoteLinks []models.RemoteLink          // With Config.FetchRemoteLinks
+	transitions []models.AvailableTransition // With Config.FetchTransitions
+}
+
+// storeIssue writes a fetched issue to the cache and runs the OnIssueCached hook.
+// It reports whether the issue changed; unchanged issues are not rewritten
+// when the cache supports change detection, only touched so their fetch
+// time records the check.
+func (s *Scraper) storeIssue(fetched *fetchedIssue) (bool, error) {
+	record := &models.CachedIssue{CacheMetadata: fetchMetadata(fetched), JiraData: fetched.Issue}
+	if err := s.applyTransforms(record); err != nil {
+		return false, err
+	}
+
+	changed := true
+	if detector, ok := s.cache.(cache.ChangeDetector); ok {
+		var err error
+		if changed, err = detector.WriteIssueIfChanged(record.JiraData, record.CacheMetadata); err != nil {
+			return false, err
+		}
+		if !changed {
+			s.touchIssue(record.JiraData.Key)
+		}
+	} else if _, err := s.cache.WriteIssueWithMetadata(record.JiraData, record.CacheMetadata); err != nil {
+		return false, err
+	}
+
+	if raw, ok := s.cache.(cache.RawStore); ok && len(s.config.Transforms) == 0 {
+		if err := raw.WriteRaw(fetched.Issue.ID, fetched.Raw); err != nil {
+			s.logf(slog.LevelWarn, "Warning: failed to store raw response for %s: %v", fetched.Issue.Key, err)
+		}
+	}
+
+	if changed && s.config.OnIssueCached != nil {
+		cached, err := s.cache.GetIssue(record.JiraData.Key)
+		if err != nil {
+			s.logf(slog.LevelWarn, "Warning: failed to read back %s for hook: %v", record.JiraData.Key, err)
+			return true, nil
+		}
+		s.runHook(cached)
+	}
+
+	return changed, nil
+}
+
+// touchIssue marks a cached issue as freshly checked, if the cache supports it
+func (s *Scraper) touchIssue(key string) {
+	toucher, ok := s.cache.(cache.Toucher)
+	if !ok {
+		return
+	}
+	if err := toucher.Touch(key); err != nil {
+		s.logf(slog.LevelWarn, "Warning: failed to touch %s: %v", key, err)
+	}
+}
+
+// runHook invokes OnIssueCached, recovering from panics so a broken hook
+// cannot abort the scrape
+func (s *Scraper) runHook(cached *models.CachedIssue) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logf(slog.LevelWarn, "Warning: OnIssueCached hook panicked for %s: %v", cached.JiraData.Key, r)
+		}
+	}()
+	s.config.OnIssueCached(context.Background(), cached)
+}
+
+// fetchMetadata builds the cache metadata for a fetched issue
+func fetchMetadata(fetched *fetchedIssue) models.CacheMetadata {
+	return models.CacheMetadata{
+		APICallDurationMS: fetched.Duration.Milliseconds(),
+		ETag:              fetched.ETag,
+		LastModified:      fetched.LastModified,
+		Renamed:           fetched.renamed,
+		RemoteLinks:       fetched.remoteLinks,
+		Transitions:       fetched.transitions,
+	}
+}
+
+// RefreshChangelog re-fetches only the changelog of a cached issue and
+// stores it with the cached fields, updating the fetch time. This keeps
+// history complete on active issues without re-downloading the whole issue.
+func (s *Scraper) RefreshChangelog(key string) error {
+	cached, err := s.cache.GetIssue(key)
+	if err != nil {
+		return fmt.Errorf("failed to read cached issue: %w", err)
+	}
+	if cached.JiraData == nil {
+		return fmt.Errorf("cached issue %s has no data", key)
+	}
+
+	start := time.Now()
+	changelog, err := s.client.GetChangelog(key)
+	if err != nil {
+		return fmt.Errorf("failed to fetch changelog: %w", err)
+	}
+
+	// Transform only the fetched changelog: the cached record was
+	// transformed when it was stored, and transforms such as hashing
+	// redaction must not be applied twice
+	fresh := &models.CachedIssue{JiraData: &models.IssueWithHistory{
+		Issue:     models.Issue{ID: cached.JiraData.ID, Key: cached.JiraData.Key},
+		Changelog: changelog,
+	}}
+	if err := s.applyTransforms(fresh); err != nil {
+		return err
+	}
+
+	// Merge rather than replace so entries the endpoint no longer returns
+	// are kept, without duplicating the ones it does
+	cached.JiraData.Changelog = history.Merge(cached.JiraData.Changelog, fresh.JiraData.Changelog)
+
+	// The cached validators describe the old response, so drop them, and
+	// let the cache stamp the current fetch time and identity
+	meta := cached.CacheMetadata
+	meta.FetchedAt = time.Time{}
+	meta.FetchedBy = ""
+	meta.APICallDurationMS = time.Since(start).Milliseconds()
+	meta.ETag = ""
+	meta.LastModified = ""
+
+	if _, err := s.cache.WriteIssueWithMetadata(cached.JiraData, meta); err != nil {
+		return fmt.Errorf("failed to cache issue: %w", err)
+	}
+
+	s.logf(slog.LevelInfo, "Refreshed changelog for %s (%d entries)", key, len(cached.JiraData.Changelog.Histories))
+	return nil
+}
+
+// SyncFieldMeta fetches the instance's field definitions and stores them in
+// the cache so exporters can render custom fields by name
+func (s *Scraper) SyncFieldMeta() ([]models.FieldMeta, error) {
+	fields, err := s.client.GetFields()
+	if err != nil {
+		return nil, err
+	}
+
+	store, ok := s.cache.(cache.MetaStore)
+	if !ok {
+		return nil, fmt.Errorf("cache does not support metadata storage")
+	}
+	if err := store.WriteMeta("fields", fields); err != nil {
+		return nil, err
+	}
+
+	s.logf(slog.LevelInfo, "Cached %d field definitions", len(fields))
+	return fields, nil
+}
+
+// SyncInstanceMeta fetches the instance's statuses and issue types and
+// stores them in the cache, so tools can validate scraped values and build
+// lookup tables that include values no cached issue uses yet
+func (s *Scraper) SyncInstanceMeta() error {
+	store, ok := s.cache.(cache.MetaStore)
+	if !ok {
+		return fmt.Errorf("cache does not support metadata storage")
+	}
+
+	statuses, err := s.client.GetStatuses()
+	if err != nil {
+		return err
+	}
+	if err := store.WriteMeta("statuses", statuses); err != nil {
+		return err
 	}
 
+	types, err := s.client.GetIssueTypes()
+	if err != nil {
+		return err
+	}
+	if err := store.WriteMeta("issuetypes", types); err != nil {
+		return err
+	}
+
+	s.logf(slog.LevelInfo, "Cached %d statuses and %d issue types", len(statuses), len(types))
 	return nil
 }
 
+// SyncProjectStatuses fetches the statuses of each issue type in a project,
+// with their categories, and stores them in the cache as
+// .meta/project-<project>-statuses.json, so cycle-time analysis can tell
+// which statuses are done without hardcoding their names
+func (s *Scraper) SyncProjectStatuses(project string) ([]models.IssueTypeStatuses, error) {
+	store, ok := s.cache.(cache.MetaStore)
+	if !ok {
+		return nil, fmt.Errorf("cache does not support metadata storage")
+	}
+
+	types, err := s.client.GetProjectStatuses(project)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.WriteMeta("project-"+project+"-statuses", types); err != nil {
+		return nil, err
+	}
+
+	s.logf(slog.LevelInfo, "Cached statuses of %d issue types in %s", len(types), project)
+	return types, nil
+}
+
+// ValidationReport summarizes a cache integrity check
+type ValidationReport struct {
+	Total  int
+	OK     int
+	Failed map[string]string // Issue key -> reason
+}
+
+// ValidateCache checks cache integrity by reading and decoding every cached
+// issue, using Config.Workers concurrent readers
+func (s *Scraper) ValidateCache() (*ValidationReport, error) {
+	s.logf(slog.LevelInfo, "Validating cache...")
+
+	keys, err := s.cache.ListIssues()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cached issues: %w", err)
+	}
+
+	s.logf(slog.LevelInfo, "Found %d cached issues", len(keys))
+
+	report := &ValidationReport{
+		Total:  len(keys),
+		Failed: map[string]string{},
+	}
+
+	jobs := make(chan string)
+	go func() {
+		defer close(jobs)
+		for _, key := range keys {
+			jobs <- key
+		}
+	}()
+
+	var mu sync.Mutex
+	var workers sync.WaitGroup
+	for i := 0; i < s.config.Workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for key := range jobs {
+				reason := ""
+				cached, err := s.cache.GetIssue(key)
+				if err != nil {
+					reason = err.Error()
+				} else if cached.JiraData == nil {
+					reason = "cached record has no issue data"
+				}
+
+				mu.Lock()
+				if reason != "" {
+					report.Failed[key] = reason
+				} else {
+					report.OK++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	workers.Wait()
+
+	if len(report.Failed) > 0 {
+		for key, reason := range report.Failed {
+			s.logf(slog.LevelError, "Error reading %s: %s", key, reason)
+		}
+		s.logf(slog.LevelError, "Cache validation found %d errors", len(report.Failed))
+	} else {
+		s.logf(slog.LevelInfo, "Cache validation passed")
+	}
+
+	return report, nil
+}
+
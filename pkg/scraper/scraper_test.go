@@ -0,0 +1,293 @@
+package scraper
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jctanner/go-jira-scraper/pkg/jira"
+)
+
+func TestUnchangedIssueIsTouched(t *testing.T) {
+	client := jira.New("https://jira.example.com", "token")
+	client.SetDoer(&issueDoer{})
+	client.SetRequestDelay(0)
+	store := newDiskCache(t)
+	s := New(client, store, Config{})
+	defer s.Close()
+
+	if err := s.ScrapeIssue("P-1"); err != nil {
+		t.Fatalf("ScrapeIssue: %v", err)
+	}
+	first, err := store.GetIssue("P-1")
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if err := s.ScrapeIssue("P-1"); err != nil {
+		t.Fatalf("ScrapeIssue: %v", err)
+	}
+	second, err := store.GetIssue("P-1")
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	if !second.CacheMetadata.FetchedAt.After(first.CacheMetadata.FetchedAt) {
+		t.Errorf("fetched_at = %v after an unchanged refetch, want later than %v", second.CacheMetadata.FetchedAt, first.CacheMetadata.FetchedAt)
+	}
+	if second.CacheMetadata.ContentHash != first.CacheMetadata.ContentHash {
+		t.Error("content hash changed for identical content")
+	}
+}
+
+func TestRefreshChangelogStampsCurrentFetchedBy(t *testing.T) {
+	store := newDiskCache(t)
+	store.SetFetchedBy("old-tool/1.0")
+	if _, err := store.WriteIssue(authoredIssue("Alice"), 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+	store.SetFetchedBy("new-tool/2.0")
+
+	client := jira.New("https://jira.example.com", "token")
+	client.SetDoer(&changelogDoer{body: `{"total":0,"isLast":true,"values":[]}`})
+	client.SetRequestDelay(0)
+	s := New(client, store, Config{})
+	defer s.Close()
+
+	if err := s.RefreshChangelog("PROJ-1"); err != nil {
+		t.Fatalf("RefreshChangelog: %v", err)
+	}
+	cached, err := store.GetIssue("PROJ-1")
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	if cached.CacheMetadata.FetchedBy != "new-tool/2.0" {
+		t.Errorf("fetched_by = %q, want new-tool/2.0", cached.CacheMetadata.FetchedBy)
+	}
+}
+
+func TestProjectOverrideLeavesSharedSettings(t *testing.T) {
+	client := jira.New("https://jira.example.com", "token")
+	client.SetRequestDelay(200 * time.Millisecond)
+	s := New(client, newDiskCache(t), Config{
+		Workers: 4,
+		ProjectOverrides: map[string]ProjectOverride{
+			"FAST": {Workers: 16, RequestDelay: -1},
+			"SLOW": {RequestDelay: time.Second},
+		},
+	})
+	defer s.Close()
+
+	tests := []struct {
+		project string
+		workers int
+		delay   time.Duration
+	}{
+		{"FAST", 16, 0},
+		{"SLOW", 4, time.Second},
+		{"OTHER", 4, 200 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		run := s.forProject(tt.project)
+		if run.config.Workers != tt.workers || run.workerDelay() != tt.delay {
+			t.Errorf("%s: %d workers, %v delay, want %d, %v", tt.project, run.config.Workers, run.workerDelay(), tt.workers, tt.delay)
+		}
+		if run.running != s.running || run.ctx != s.ctx {
+			t.Errorf("%s: run does not share the scraper's lifecycle", tt.project)
+		}
+	}
+	if s.config.Workers != 4 || client.RequestDelay() != 200*time.Millisecond {
+		t.Errorf("shared settings changed to %d workers, %v delay", s.config.Workers, client.RequestDelay())
+	}
+}
+
+func TestScrapeLogsThroughClientLogger(t *testing.T) {
+	var logs bytes.Buffer
+	client := jira.New("https://jira.example.com", "token")
+	client.SetDoer(&issueDoer{})
+	client.SetRequestDelay(0)
+	client.SetLogger(jira.NewJSONLogger(&logs))
+	s := New(client, newDiskCache(t), Config{})
+	defer s.Close()
+
+	if err := s.ScrapeIssue("P-1"); err != nil {
+		t.Fatalf("ScrapeIssue: %v", err)
+	}
+
+	var messages []string
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var event struct {
+			Level string `json:"level"`
+			Msg   string `json:"msg"`
+		}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", line, err)
+		}
+		messages = append(messages, event.Level+" "+event.Msg)
+	}
+	for _, want := range []string{"INFO Fetching issue: P-1", "INFO Successfully fetched and cached P-1"} {
+		found := false
+		for _, msg := range messages {
+			found = found || msg == want
+		}
+		if !found {
+			t.Errorf("no %q event in %q", want, messages)
+		}
+	}
+}
+
+// projectDoer answers searches with P-1 and P-2 and fetches them through
+// issueDoer, failing P-2 while fail is set
+type projectDoer struct {
+	issueDoer
+	fail bool
+}
+
+func (d *projectDoer) Do(req *http.Request) (*http.Response, error) {
+	respond := func(status int, body string) (*http.Response, error) {
+		return &http.Response{
+			StatusCode:    status,
+			Header:        http.Header{},
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/search"):
+		return respond(200, `{"startAt":0,"maxResults":10,"total":2,"issues":[{"id":"1","key":"P-1"},{"id":"2","key":"P-2"}]}`)
+	case d.fail && strings.HasSuffix(req.URL.Path, "/P-2"):
+		return respond(400, `{"errorMessages":["bad request"]}`)
+	}
+	return d.issueDoer.Do(req)
+}
+
+func TestSyncStateCountsErrors(t *testing.T) {
+	doer := &projectDoer{fail: true}
+	client := jira.New("https://jira.example.com", "token")
+	client.SetDoer(doer)
+	client.SetRequestDelay(0)
+	store := newDiskCache(t)
+	s := New(client, store, Config{FullSync: true})
+	defer s.Close()
+
+	if _, err := s.ScrapeProject("P"); err != nil {
+		t.Fatalf("ScrapeProject: %v", err)
+	}
+	state, err := store.LastSync("P")
+	if err != nil {
+		t.Fatalf("LastSync: %v", err)
+	}
+	if state.Errors != 1 || state.IssuesProcessed != 2 || !state.LastSyncTime().IsZero() || !state.UpdatedWatermark.IsZero() {
+		t.Errorf("state after a failed scrape = %+v, want 1 error and no sync time or watermark", state)
+	}
+
+	doer.fail = false
+	if _, err := s.ScrapeProject("P"); err != nil {
+		t.Fatalf("ScrapeProject: %v", err)
+	}
+	if state, err = store.LastSync("P"); err != nil {
+		t.Fatalf("LastSync: %v", err)
+	}
+	if state.Errors != 0 || state.LastFullSync.IsZero() || state.UpdatedWatermark.IsZero() {
+		t.Errorf("state after a clean scrape = %+v, want a full sync time and watermark", state)
+	}
+}
+
+func TestScrapeDateRangeRejectsSubMinuteBounds(t *testing.T) {
+	doer := &projectDoer{}
+	client := jira.New("https://jira.example.com", "token")
+	client.SetDoer(doer)
+	client.SetRequestDelay(0)
+	s := New(client, newDiskCache(t), Config{})
+	defer s.Close()
+
+	at := time.Date(2024, 3, 5, 14, 7, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		from, to time.Time
+		wantErr  bool
+	}{
+		{name: "whole minutes", from: at, to: at.Add(time.Minute)},
+		{name: "sub-minute range", from: at, to: at.Add(30 * time.Second), wantErr: true},
+		{name: "seconds in from", from: at.Add(-90 * time.Second), to: at, wantErr: true},
+		{name: "nanoseconds in to", from: at, to: at.Add(time.Hour + time.Nanosecond), wantErr: true},
+		{name: "empty", from: at, to: at, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.ScrapeDateRange("P", tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ScrapeDateRange error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// extrasDoer answers remote link and transition requests, and issue
+// requests as issueDoer does
+type extrasDoer struct {
+	issueDoer
+}
+
+func (d *extrasDoer) Do(req *http.Request) (*http.Response, error) {
+	var body string
+	switch path.Base(req.URL.Path) {
+	case "remotelink":
+		body = `[{"id":10,"object":{"url":"https://example.com/pr/1","title":"PR 1"}}]`
+	case "transitions":
+		body = `{"transitions":[{"id":"21","name":"Close"}]}`
+	default:
+		return d.issueDoer.Do(req)
+	}
+	return &http.Response{
+		StatusCode:    200,
+		Header:        http.Header{},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func TestFetchedExtrasStoredInMetadata(t *testing.T) {
+	client := jira.New("https://jira.example.com", "token")
+	client.SetDoer(&extrasDoer{})
+	client.SetRequestDelay(0)
+	store := newDiskCache(t)
+	s := New(client, store, Config{FetchRemoteLinks: true, FetchTransitions: true})
+	defer s.Close()
+
+	if err := s.ScrapeIssue("P-1"); err != nil {
+		t.Fatalf("ScrapeIssue: %v", err)
+	}
+	cached, err := store.GetIssue("P-1")
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	meta := cached.CacheMetadata
+	if len(meta.RemoteLinks) != 1 || meta.RemoteLinks[0].Object.URL != "https://example.com/pr/1" {
+		t.Errorf("RemoteLinks = %+v, want the PR link", meta.RemoteLinks)
+	}
+	if len(meta.Transitions) != 1 || meta.Transitions[0].Name != "Close" {
+		t.Errorf("Transitions = %+v, want Close", meta.Transitions)
+	}
+
+	data, err := json.Marshal(cached.JiraData)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var jiraData map[string]any
+	if err := json.Unmarshal(data, &jiraData); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	for _, name := range []string{"remotelinks", "transitions"} {
+		if _, ok := jiraData[name]; ok {
+			t.Errorf("jira_data has %q; JIRA's issue response has no such key", name)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jctanner/go-jira-scraper/pkg/cache"
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// Transform modifies an issue record after it is fetched and before it is
+// written to the cache, e.g. to redact, strip or normalize fields. It may
+// change the record in place or replace its JiraData. An error leaves the
+// issue uncached and is reported as a failure of that issue.
+//
+// Transforms see each piece of fetched data once. RefreshChangelog passes
+// a record holding only the issue's ID, key and fetched changelog, and
+// merges the result into the already transformed cached record.
+type Transform func(cached *models.CachedIssue) error
+
+// applyTransforms runs Config.Transforms over a record in order
+func (s *Scraper) applyTransforms(cached *models.CachedIssue) error {
+	for i, transform := range s.config.Transforms {
+		if err := transform(cached); err != nil {
+			return fmt.Errorf("transform %d failed: %w", i, err)
+		}
+		if cached.JiraData == nil {
+			return fmt.Errorf("transform %d removed the issue data", i)
+		}
+	}
+	return nil
+}
+
+// RedactTransform returns a transform applying a redaction config, as an
+// alternative to configuring redaction on the cache itself
+func RedactTransform(config cache.RedactionConfig) (Transform, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return func(cached *models.CachedIssue) error {
+		redacted, err := config.Redact(cached.JiraData)
+		if err != nil {
+			return err
+		}
+		cached.JiraData = redacted
+		return nil
+	}, nil
+}
+
+// StripFieldsTransform returns a transform removing the named fields from
+// issues, by JIRA field ID (e.g. "description", "comment",
+// "customfield_10020"). The expansions "changelog" and "renderedFields",
+// and the "remotelinks" and "transitions" kept in the cache metadata, can
+// be named too.
+func StripFieldsTransform(fields ...string) Transform {
+	expansions := map[string]bool{"changelog": true, "renderedFields": true, "remotelinks": true, "transitions": true}
+
+	return func(cached *models.CachedIssue) error {
+		issue := cached.JiraData
+		var stripFields bool
+		for _, field := range fields {
+			switch field {
+			case "changelog":
+				issue.Changelog = nil
+			case "renderedFields":
+				issue.RenderedFields = nil
+			case "remotelinks":
+				cached.CacheMetadata.RemoteLinks = nil
+			case "transitions":
+				cached.CacheMetadata.Transitions = nil
+			default:
+				stripFields = true
+			}
+		}
+		if !stripFields || issue.Fields == nil {
+			return nil
+		}
+
+		// Round trip through a map so any field, custom or not, can be
+		// removed by its JSON name
+		data, err := json.Marshal(issue.Fields)
+		if err != nil {
+			return fmt.Errorf("failed to marshal fields: %w", err)
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(data, &all); err != nil {
+			return fmt.Errorf("failed to decode fields: %w", err)
+		}
+		for _, field := range fields {
+			if !expansions[field] {
+				delete(all, field)
+			}
+		}
+		if data, err = json.Marshal(all); err != nil {
+			return fmt.Errorf("failed to marshal fields: %w", err)
+		}
+
+		var stripped models.IssueFields
+		if err := json.Unmarshal(data, &stripped); err != nil {
+			return fmt.Errorf("failed to decode fields: %w", err)
+		}
+		issue.Fields = &stripped
+		return nil
+	}
+}
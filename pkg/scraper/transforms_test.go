@@ -0,0 +1,219 @@
+package scraper
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jctanner/go-jira-scraper/pkg/cache"
+	"github.com/jctanner/go-jira-scraper/pkg/jira"
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// newDiskCache returns an initialized disk cache in a temporary directory
+func newDiskCache(t *testing.T) *cache.DiskCache {
+	t.Helper()
+	store := cache.NewWithHost(t.TempDir(), "https://jira.example.com")
+	if err := store.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	return store
+}
+
+// authoredIssue returns an issue with a description, a custom field and one
+// history entry per author
+func authoredIssue(authors ...string) *models.IssueWithHistory {
+	issue := &models.IssueWithHistory{
+		Issue: models.Issue{ID: "10001", Key: "PROJ-1", Fields: &models.IssueFields{
+			Summary:     "summary",
+			Description: models.RichText{Text: "description"},
+			Custom:      map[string]json.RawMessage{"customfield_10020": json.RawMessage(`"sprint"`)},
+		}},
+		Changelog: &models.Changelog{},
+	}
+	for i, author := range authors {
+		issue.Changelog.Histories = append(issue.Changelog.Histories, models.History{
+			ID:      string(rune('1' + i)),
+			Author:  &models.User{Name: author, DisplayName: author},
+			Created: "2024-01-0" + string(rune('1'+i)) + "T00:00:00.000+0000",
+		})
+	}
+	return issue
+}
+
+func TestApplyTransforms(t *testing.T) {
+	var order []string
+	record := func(name string) Transform {
+		return func(*models.CachedIssue) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+	failed := errors.New("boom")
+
+	tests := []struct {
+		name       string
+		transforms []Transform
+		wantErr    string
+		wantOrder  string
+	}{
+		{name: "none"},
+		{name: "in order", transforms: []Transform{record("a"), record("b")}, wantOrder: "a,b"},
+		{
+			name:       "error stops the pipeline",
+			transforms: []Transform{record("a"), func(*models.CachedIssue) error { return failed }, record("c")},
+			wantErr:    "transform 1 failed: boom",
+			wantOrder:  "a",
+		},
+		{
+			name:       "removing the data is an error",
+			transforms: []Transform{func(c *models.CachedIssue) error { c.JiraData = nil; return nil }},
+			wantErr:    "transform 0 removed the issue data",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order = nil
+			s := &Scraper{config: Config{Transforms: tt.transforms}}
+			err := s.applyTransforms(&models.CachedIssue{JiraData: authoredIssue()})
+			if (err == nil) != (tt.wantErr == "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+			if got := strings.Join(order, ","); got != tt.wantOrder {
+				t.Errorf("ran %q, want %q", got, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestRedactTransform(t *testing.T) {
+	if _, err := RedactTransform(cache.RedactionConfig{Fields: map[string]cache.RedactionMode{"assignee": cache.RedactDrop}}); err == nil {
+		t.Error("RedactTransform accepted a path without an attribute")
+	}
+
+	transform, err := RedactTransform(cache.RedactionConfig{Fields: map[string]cache.RedactionMode{"changelog.displayName": cache.RedactDrop}})
+	if err != nil {
+		t.Fatalf("RedactTransform: %v", err)
+	}
+	issue := authoredIssue("Alice")
+	record := &models.CachedIssue{JiraData: issue}
+	if err := transform(record); err != nil {
+		t.Fatalf("transform: %v", err)
+	}
+	if author := record.JiraData.Changelog.Histories[0].Author; author.DisplayName != "" || author.Name != "Alice" {
+		t.Errorf("author = %+v, want the display name dropped", author)
+	}
+	if issue.Changelog.Histories[0].Author.DisplayName != "Alice" {
+		t.Error("transform modified the fetched issue")
+	}
+}
+
+func TestStripFieldsTransform(t *testing.T) {
+	record := &models.CachedIssue{JiraData: authoredIssue("Alice")}
+	record.CacheMetadata.RemoteLinks = []models.RemoteLink{{ID: 10}}
+	record.CacheMetadata.Transitions = []models.AvailableTransition{{ID: "21", Name: "Close"}}
+	if err := StripFieldsTransform("description", "customfield_10020", "changelog", "remotelinks")(record); err != nil {
+		t.Fatalf("transform: %v", err)
+	}
+
+	issue := record.JiraData
+	if issue.Fields.Description.Text != "" || issue.Fields.Custom["customfield_10020"] != nil {
+		t.Errorf("fields = %+v, want description and customfield_10020 removed", issue.Fields)
+	}
+	if issue.Fields.Summary != "summary" {
+		t.Errorf("summary = %q, want it kept", issue.Fields.Summary)
+	}
+	if issue.Changelog != nil {
+		t.Error("changelog was kept")
+	}
+	if meta := record.CacheMetadata; meta.RemoteLinks != nil || len(meta.Transitions) != 1 {
+		t.Errorf("metadata = %+v, want remote links removed and transitions kept", meta)
+	}
+}
+
+// changelogDoer answers changelog requests with a single page of body
+type changelogDoer struct {
+	body string
+}
+
+func (d *changelogDoer) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode:    200,
+		Header:        http.Header{},
+		Body:          io.NopCloser(strings.NewReader(d.body)),
+		ContentLength: int64(len(d.body)),
+		Request:       req,
+	}, nil
+}
+
+func TestRefreshChangelogTransformsOnlyFetchedEntries(t *testing.T) {
+	redact, err := RedactTransform(cache.RedactionConfig{
+		Fields: map[string]cache.RedactionMode{"changelog.name": cache.RedactHash},
+		Salt:   "salt",
+	})
+	if err != nil {
+		t.Fatalf("RedactTransform: %v", err)
+	}
+
+	// Store the issue as a scrape would, already redacted
+	store := newDiskCache(t)
+	stored := &models.CachedIssue{JiraData: authoredIssue("Alice")}
+	if err := redact(stored); err != nil {
+		t.Fatalf("transform: %v", err)
+	}
+	if _, err := store.WriteIssue(stored.JiraData, 0); err != nil {
+		t.Fatalf("WriteIssue: %v", err)
+	}
+
+	want := &models.CachedIssue{JiraData: authoredIssue("Alice", "Bob")}
+	if err := redact(want); err != nil {
+		t.Fatalf("transform: %v", err)
+	}
+
+	// The endpoint returns only the newer entry
+	page, _ := json.Marshal(models.ChangelogPage{Total: 1, IsLast: true, Values: authoredIssue("Alice", "Bob").Changelog.Histories[1:]})
+	client := jira.New("https://jira.example.com", "token")
+	client.SetDoer(&changelogDoer{body: string(page)})
+	client.SetRequestDelay(0)
+	s := New(client, store, Config{Transforms: []Transform{redact}})
+	defer s.Close()
+
+	if err := s.RefreshChangelog("PROJ-1"); err != nil {
+		t.Fatalf("RefreshChangelog: %v", err)
+	}
+	cached, err := store.GetIssue("PROJ-1")
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	histories := cached.JiraData.Changelog.Histories
+	if len(histories) != 2 {
+		t.Fatalf("changelog has %d entries, want 2", len(histories))
+	}
+	for i, history := range histories {
+		if got, want := history.Author.Name, want.JiraData.Changelog.Histories[i].Author.Name; got != want {
+			t.Errorf("history %s author = %q, want %q (hashed once)", history.ID, got, want)
+		}
+	}
+}
+
+func TestNewWarnsWhenTransformsSkipRawStorage(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	store := newDiskCache(t)
+	store.SetStoreRaw(true)
+	s := New(jira.New("https://jira.example.com", "token"), store, Config{Transforms: []Transform{StripFieldsTransform("description")}})
+	defer s.Close()
+
+	if !strings.Contains(logs.String(), "raw responses are not stored") {
+		t.Errorf("no warning logged; got %q", logs.String())
+	}
+}
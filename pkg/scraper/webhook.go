@@ -0,0 +1,100 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// WebhookPayload is the compact JSON body posted for each cached issue
+type WebhookPayload struct {
+	ID        string    `json:"id"`
+	Key       string    `json:"key"`
+	Summary   string    `json:"summary,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	Updated   string    `json:"updated,omitempty"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// NewWebhookHook returns an OnIssueCached hook that POSTs a WebhookPayload to
+// webhookURL, retrying failed deliveries with exponential backoff. Delivery
+// failures are logged as plain text via the log package and never abort
+// the scrape.
+func NewWebhookHook(webhookURL string, maxRetries int) func(ctx context.Context, cached *models.CachedIssue) {
+	httpClient := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+
+	return func(ctx context.Context, cached *models.CachedIssue) {
+		if cached == nil || cached.JiraData == nil {
+			return
+		}
+
+		payload := WebhookPayload{
+			ID:        cached.JiraData.ID,
+			Key:       cached.JiraData.Key,
+			FetchedAt: cached.CacheMetadata.FetchedAt,
+		}
+		if fields := cached.JiraData.Fields; fields != nil {
+			payload.Summary = fields.Summary
+			payload.Updated = fields.Updated
+			if fields.Status != nil {
+				payload.Status = fields.Status.Name
+			}
+		}
+
+		body, err := json.Marshal(payload)
+		if err != nil {
+			log.Printf("Webhook: failed to marshal payload for %s: %v", payload.Key, err)
+			return
+		}
+
+		for attempt := 0; attempt <= maxRetries; attempt++ {
+			if attempt > 0 {
+				waitTime := time.Duration(1<<uint(attempt)) * time.Second
+				select {
+				case <-ctx.Done():
+					log.Printf("Webhook: delivery for %s cancelled: %v", payload.Key, ctx.Err())
+					return
+				case <-time.After(waitTime):
+				}
+			}
+
+			err = postWebhook(ctx, httpClient, webhookURL, body)
+			if err == nil {
+				return
+			}
+			log.Printf("Webhook: delivery attempt %d/%d for %s failed: %v", attempt+1, maxRetries+1, payload.Key, err)
+		}
+
+		log.Printf("Webhook: giving up on %s after %d attempts", payload.Key, maxRetries+1)
+	}
+}
+
+// postWebhook sends a single webhook delivery
+func postWebhook(ctx context.Context, httpClient *http.Client, webhookURL string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+// Package server serves a scraped cache over a read-only subset of the JIRA
+// REST API, so tools that read from JIRA can run against the cache offline.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jctanner/go-jira-scraper/pkg/cache"
+	"github.com/jctanner/go-jira-scraper/pkg/jql"
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// maxSearchResults caps maxResults on search, like JIRA does
+const maxSearchResults = 1000
+
+// Server answers JIRA issue and search requests from a cache
+type Server struct {
+	cache cache.Cache
+	mux   *http.ServeMux
+}
+
+// New creates a server reading from c
+func New(c cache.Cache) *Server {
+	s := &Server{cache: c, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /rest/api/2/issue/{key}", s.handleIssue)
+	s.mux.HandleFunc("GET /rest/api/2/issue/{key}/remotelink", s.handleRemoteLinks)
+	s.mux.HandleFunc("GET /rest/api/2/search", s.handleSearch)
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleIssue serves GET /rest/api/2/issue/{key}
+func (s *Server) handleIssue(w http.ResponseWriter, r *http.Request) {
+	cached, err := s.cache.GetIssue(r.PathValue("key"))
+	if err != nil || cached.JiraData == nil {
+		writeError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+
+	query := r.URL.Query()
+	issue, err := projectFields(expandIssue(cached, query.Get("expand")), query.Get("fields"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, issue)
+}
+
+// handleRemoteLinks serves GET /rest/api/2/issue/{key}/remotelink from the
+// remote links stored in the cache metadata
+func (s *Server) handleRemoteLinks(w http.ResponseWriter, r *http.Request) {
+	cached, err := s.cache.GetIssue(r.PathValue("key"))
+	if err != nil || cached.JiraData == nil {
+		writeError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+
+	links := cached.CacheMetadata.RemoteLinks
+	if links == nil {
+		links = []models.RemoteLink{}
+	}
+	writeJSON(w, http.StatusOK, links)
+}
+
+// searchResponse mirrors the JIRA search response
+type searchResponse struct {
+	StartAt    int               `json:"startAt"`
+	MaxResults int               `json:"maxResults"`
+	Total      int               `json:"total"`
+	Issues     []json.RawMessage `json:"issues"`
+}
+
+// handleSearch serves GET /rest/api/2/search for a small JQL subset: an
+// empty query, project = X, key = X, or key in (X, Y). ORDER BY is ignored;
+// results are ordered by key.
+//
+// Matching and paging use the cached keys, so only the requested page is
+// read. Caches that cannot list current keys (see cache.CurrentKeyLister)
+// count the old keys of moved issues in the total; those keys are left out
+// of the page.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	match, err := parseFilter(query.Get("jql"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	startAt, err := intParam(query.Get("startAt"), 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "startAt must be a number")
+		return
+	}
+	maxResults, err := intParam(query.Get("maxResults"), 50)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "maxResults must be a number")
+		return
+	}
+	if maxResults > maxSearchResults {
+		maxResults = maxSearchResults
+	}
+
+	list := s.cache.ListIssues
+	if lister, ok := s.cache.(cache.CurrentKeyLister); ok {
+		list = lister.ListCurrentIssues
+	}
+	keys, err := list()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var matched []string
+	for _, key := range keys {
+		if match(key) {
+			matched = append(matched, key)
+		}
+	}
+	sortKeys(matched)
+
+	response := searchResponse{
+		StartAt:    startAt,
+		MaxResults: maxResults,
+		Total:      len(matched),
+		Issues:     []json.RawMessage{},
+	}
+	page := matched[min(startAt, len(matched)):min(startAt+maxResults, len(matched))]
+	for _, key := range page {
+		cached, err := s.cache.GetIssue(key)
+		if err != nil || cached.JiraData == nil || cached.JiraData.Key != key {
+			// Unreadable records and aliases of moved issues
+			continue
+		}
+
+		issue, err := projectFields(expandIssue(cached, query.Get("expand")), query.Get("fields"))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		response.Issues = append(response.Issues, issue)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+var (
+	projectPattern = regexp.MustCompile(`(?i)^project\s*=\s*("(?:[^"\\]|\\.)*"|[A-Za-z0-9_]+)$`)
+	keyPattern     = regexp.MustCompile(`(?i)^(?:issue)?key\s*=\s*("(?:[^"\\]|\\.)*"|[A-Za-z0-9_-]+)$`)
+	keyInPattern   = regexp.MustCompile(`(?i)^(?:issue)?key\s+in\s*\(([^)]*)\)$`)
+)
+
+// parseFilter turns the supported JQL subset into a key predicate
+func parseFilter(query string) (func(key string) bool, error) {
+	where, _ := jql.SplitOrderBy(query)
+	for strings.HasPrefix(where, "(") && strings.HasSuffix(where, ")") {
+		where = strings.TrimSpace(where[1 : len(where)-1])
+	}
+
+	switch {
+	case where == "":
+		return func(string) bool { return true }, nil
+	case projectPattern.MatchString(where):
+		prefix := strings.ToUpper(unquote(projectPattern.FindStringSubmatch(where)[1])) + "-"
+		return func(key string) bool { return strings.HasPrefix(strings.ToUpper(key), prefix) }, nil
+	case keyPattern.MatchString(where):
+		want := strings.ToUpper(unquote(keyPattern.FindStringSubmatch(where)[1]))
+		return func(key string) bool { return strings.ToUpper(key) == want }, nil
+	case keyInPattern.MatchString(where):
+		want := map[string]bool{}
+		for _, k := range strings.Split(keyInPattern.FindStringSubmatch(where)[1], ",") {
+			want[strings.ToUpper(unquote(strings.TrimSpace(k)))] = true
+		}
+		return func(key string) bool { return want[strings.ToUpper(key)] }, nil
+	}
+	return nil, fmt.Errorf("unsupported JQL for the offline cache: %q (supported: project = X, key = X, key in (X, Y))", query)
+}
+
+// unquote strips JQL string literal quoting
+func unquote(value string) string {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		value = value[1 : len(value)-1]
+		value = strings.ReplaceAll(value, `\"`, `"`)
+		value = strings.ReplaceAll(value, `\\`, `\`)
+	}
+	return value
+}
+
+// sortKeys orders issue keys by project, then numerically by issue number
+func sortKeys(keys []string) {
+	split := func(key string) (string, int) {
+		i := strings.LastIndex(key, "-")
+		if i < 0 {
+			return key, 0
+		}
+		n, _ := strconv.Atoi(key[i+1:])
+		return key[:i], n
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		pi, ni := split(keys[i])
+		pj, nj := split(keys[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return ni < nj
+	})
+}
+
+// issueResponse is an issue as JIRA serves it, including the cached
+// available transitions when expand=transitions is requested
+type issueResponse struct {
+	*models.IssueWithHistory
+	Transitions []models.AvailableTransition `json:"transitions,omitempty"`
+}
+
+// expandIssue returns a cached issue with only the expansions requested by
+// the JIRA expand parameter, a comma-separated list such as
+// "changelog,renderedFields"
+func expandIssue(cached *models.CachedIssue, expand string) *issueResponse {
+	requested := map[string]bool{}
+	for _, name := range strings.Split(expand, ",") {
+		requested[strings.TrimSpace(name)] = true
+	}
+
+	// Copy rather than modify the cached issue
+	expanded := *cached.JiraData
+	if !requested["changelog"] {
+		expanded.Changelog = nil
+	}
+	if !requested["renderedFields"] {
+		expanded.RenderedFields = nil
+	}
+	response := &issueResponse{IssueWithHistory: &expanded}
+	if requested["transitions"] {
+		response.Transitions = cached.CacheMetadata.Transitions
+	}
+	return response
+}
+
+// projectFields renders an issue with only the requested fields, following the
+// JIRA fields parameter: a comma-separated list of field IDs, where empty,
+// "*all" and "*navigable" select everything and "-name" excludes a field
+func projectFields(issue *issueResponse, fields string) (json.RawMessage, error) {
+	data, err := json.Marshal(issue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal issue: %w", err)
+	}
+
+	var include, exclude []string
+	all := fields == ""
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		switch {
+		case field == "":
+		case field == "*all" || field == "*navigable":
+			all = true
+		case strings.HasPrefix(field, "-"):
+			exclude = append(exclude, field[1:])
+		default:
+			include = append(include, field)
+		}
+	}
+	if all && len(exclude) == 0 {
+		return data, nil
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode issue: %w", err)
+	}
+	var values map[string]json.RawMessage
+	if raw, ok := doc["fields"]; ok && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return nil, fmt.Errorf("failed to decode issue fields: %w", err)
+		}
+	}
+
+	projected := map[string]json.RawMessage{}
+	if all {
+		projected = values
+	} else {
+		for _, field := range include {
+			if value, ok := values[field]; ok {
+				projected[field] = value
+			}
+		}
+	}
+	for _, field := range exclude {
+		delete(projected, field)
+	}
+
+	if doc["fields"], err = json.Marshal(projected); err != nil {
+		return nil, fmt.Errorf("failed to encode issue fields: %w", err)
+	}
+	return json.Marshal(doc)
+}
+
+// intParam parses an optional non-negative integer query parameter
+func intParam(value string, def int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+	return n, nil
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Warning: failed to write response: %v", err)
+	}
+}
+
+// writeError writes a JIRA-style error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{
+		"errorMessages": []string{message},
+		"errors":        map[string]string{},
+	})
+}
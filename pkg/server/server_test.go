@@ -0,0 +1,216 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jctanner/go-jira-scraper/pkg/cache"
+	"github.com/jctanner/go-jira-scraper/pkg/models"
+)
+
+// countingCache counts the records read from a disk cache
+type countingCache struct {
+	*cache.DiskCache
+	reads atomic.Int64
+}
+
+func (c *countingCache) GetIssue(key string) (*models.CachedIssue, error) {
+	c.reads.Add(1)
+	return c.DiskCache.GetIssue(key)
+}
+
+// newTestServer serves a cache holding PROJ-1..PROJ-12, each with a
+// changelog and rendered fields, PROJ-1 also with a remote link and a
+// transition, and an issue moved from OLD-1 to PROJ-13
+func newTestServer(t *testing.T) (*httptest.Server, *countingCache) {
+	t.Helper()
+	disk := cache.NewWithHost(t.TempDir(), "https://jira.example.com")
+	if err := disk.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	write := func(id, key string, meta models.CacheMetadata) {
+		issue := &models.IssueWithHistory{
+			Issue:          models.Issue{ID: id, Key: key, Fields: &models.IssueFields{Summary: "summary " + key}},
+			Changelog:      &models.Changelog{Total: 1, Histories: []models.History{{ID: id}}},
+			RenderedFields: &models.RenderedFields{},
+		}
+		if _, err := disk.WriteIssueWithMetadata(issue, meta); err != nil {
+			t.Fatalf("WriteIssueWithMetadata: %v", err)
+		}
+	}
+	write("10001", "PROJ-1", models.CacheMetadata{
+		RemoteLinks: []models.RemoteLink{{ID: 10, Object: models.RemoteLinkObject{URL: "https://example.com/pr/1", Title: "PR 1"}}},
+		Transitions: []models.AvailableTransition{{ID: "21", Name: "Close"}},
+	})
+	for i := 2; i <= 12; i++ {
+		write(fmt.Sprint(10000+i), fmt.Sprintf("PROJ-%d", i), models.CacheMetadata{})
+	}
+	write("10013", "PROJ-13", models.CacheMetadata{Renamed: &models.RenameInfo{FromKey: "OLD-1", ToKey: "PROJ-13", DetectedAt: time.Now()}})
+
+	counting := &countingCache{DiskCache: disk}
+	server := httptest.NewServer(New(counting))
+	t.Cleanup(server.Close)
+	return server, counting
+}
+
+// getJSON fetches path from server and decodes the response into v
+func getJSON(t *testing.T, server *httptest.Server, path string, query url.Values, v any) int {
+	t.Helper()
+	resp, err := http.Get(server.URL + path + "?" + query.Encode())
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("decode %s: %v", path, err)
+	}
+	return resp.StatusCode
+}
+
+// searchResult is the part of a search response the tests check
+type searchResult struct {
+	StartAt int `json:"startAt"`
+	Total   int `json:"total"`
+	Issues  []struct {
+		Key            string           `json:"key"`
+		Fields         map[string]any   `json:"fields"`
+		Changelog      *json.RawMessage `json:"changelog"`
+		RenderedFields *json.RawMessage `json:"renderedFields"`
+	} `json:"issues"`
+}
+
+func (r searchResult) keys() []string {
+	keys := make([]string, len(r.Issues))
+	for i, issue := range r.Issues {
+		keys[i] = issue.Key
+	}
+	return keys
+}
+
+func TestSearchPagesBeforeReading(t *testing.T) {
+	server, counting := newTestServer(t)
+
+	tests := []struct {
+		name  string
+		query url.Values
+		total int
+		keys  []string
+	}{
+		{
+			name:  "first page",
+			query: url.Values{"jql": {"project = PROJ ORDER BY key"}, "maxResults": {"3"}},
+			total: 13,
+			keys:  []string{"PROJ-1", "PROJ-2", "PROJ-3"},
+		},
+		{
+			name:  "last page",
+			query: url.Values{"jql": {"project = PROJ"}, "startAt": {"10"}, "maxResults": {"5"}},
+			total: 13,
+			keys:  []string{"PROJ-11", "PROJ-12", "PROJ-13"},
+		},
+		{
+			name:  "past the end",
+			query: url.Values{"jql": {"project = PROJ"}, "startAt": {"20"}},
+			total: 13,
+			keys:  []string{},
+		},
+		{
+			name:  "moved issue under its old key",
+			query: url.Values{"jql": {"key in (OLD-1, PROJ-2)"}},
+			total: 1,
+			keys:  []string{"PROJ-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counting.reads.Store(0)
+			var result searchResult
+			if status := getJSON(t, server, "/rest/api/2/search", tt.query, &result); status != http.StatusOK {
+				t.Fatalf("status = %d", status)
+			}
+			if result.Total != tt.total || !reflect.DeepEqual(result.keys(), tt.keys) {
+				t.Errorf("total %d, keys %v, want %d, %v", result.Total, result.keys(), tt.total, tt.keys)
+			}
+			if reads := counting.reads.Load(); reads != int64(len(tt.keys)) {
+				t.Errorf("read %d records for a page of %d", reads, len(tt.keys))
+			}
+		})
+	}
+}
+
+func TestSearchHonoursExpandAndFields(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	var plain searchResult
+	getJSON(t, server, "/rest/api/2/search", url.Values{"jql": {"key = PROJ-1"}}, &plain)
+	if len(plain.Issues) != 1 || plain.Issues[0].Changelog != nil || plain.Issues[0].RenderedFields != nil {
+		t.Fatalf("unexpanded search = %+v, want no changelog or rendered fields", plain)
+	}
+
+	var expanded searchResult
+	getJSON(t, server, "/rest/api/2/search", url.Values{
+		"jql":    {"key = PROJ-1"},
+		"expand": {"changelog"},
+		"fields": {"summary"},
+	}, &expanded)
+	if len(expanded.Issues) != 1 || expanded.Issues[0].Changelog == nil || expanded.Issues[0].RenderedFields != nil {
+		t.Fatalf("expand=changelog search = %+v, want only the changelog", expanded)
+	}
+	if fields := expanded.Issues[0].Fields; len(fields) != 1 || fields["summary"] != "summary PROJ-1" {
+		t.Errorf("fields = %v, want only the summary", fields)
+	}
+}
+
+func TestIssueAndErrors(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	var issue struct {
+		Key       string           `json:"key"`
+		Changelog *json.RawMessage `json:"changelog"`
+	}
+	if status := getJSON(t, server, "/rest/api/2/issue/PROJ-2", url.Values{"expand": {"changelog"}}, &issue); status != http.StatusOK || issue.Key != "PROJ-2" || issue.Changelog == nil {
+		t.Errorf("GET issue = %d, %+v, want PROJ-2 with its changelog", status, issue)
+	}
+
+	var failure struct {
+		ErrorMessages []string `json:"errorMessages"`
+	}
+	if status := getJSON(t, server, "/rest/api/2/issue/PROJ-99", nil, &failure); status != http.StatusNotFound || len(failure.ErrorMessages) != 1 {
+		t.Errorf("GET missing issue = %d, %+v, want 404 with a message", status, failure)
+	}
+	if status := getJSON(t, server, "/rest/api/2/search", url.Values{"jql": {"summary ~ foo"}}, &failure); status != http.StatusBadRequest {
+		t.Errorf("unsupported JQL status = %d, want 400", status)
+	}
+	if status := getJSON(t, server, "/rest/api/2/search", url.Values{"startAt": {"-1"}}, &failure); status != http.StatusBadRequest {
+		t.Errorf("negative startAt status = %d, want 400", status)
+	}
+}
+
+func TestIssueServesFetchedExtras(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	var issue struct {
+		Transitions []models.AvailableTransition `json:"transitions"`
+	}
+	if status := getJSON(t, server, "/rest/api/2/issue/PROJ-1", nil, &issue); status != http.StatusOK || issue.Transitions != nil {
+		t.Errorf("GET issue = %d, %+v, want no transitions without expand", status, issue)
+	}
+	if status := getJSON(t, server, "/rest/api/2/issue/PROJ-1", url.Values{"expand": {"transitions"}}, &issue); status != http.StatusOK || len(issue.Transitions) != 1 || issue.Transitions[0].Name != "Close" {
+		t.Errorf("GET issue?expand=transitions = %d, %+v, want the Close transition", status, issue)
+	}
+
+	var links []models.RemoteLink
+	if status := getJSON(t, server, "/rest/api/2/issue/PROJ-1/remotelink", nil, &links); status != http.StatusOK || len(links) != 1 || links[0].Object.URL != "https://example.com/pr/1" {
+		t.Errorf("GET remotelink = %d, %+v, want the PR link", status, links)
+	}
+	if status := getJSON(t, server, "/rest/api/2/issue/PROJ-2/remotelink", nil, &links); status != http.StatusOK || len(links) != 0 {
+		t.Errorf("GET remotelink without links = %d, %+v, want an empty list", status, links)
+	}
+}